@@ -1,21 +1,112 @@
 package api
 
 import (
-	"os"
+	"context"
+	"database/sql"
+	"path/filepath"
 
 	"Robin-Camp/internal"
 	"Robin-Camp/internal/boxoffice"
+	"Robin-Camp/internal/config"
+	"Robin-Camp/internal/jwt"
+	"Robin-Camp/internal/lifecycle"
+	"Robin-Camp/internal/metrics"
+	"Robin-Camp/internal/moderation"
+	"Robin-Camp/internal/ratelimit"
 
+	"github.com/cloudwego/hertz/pkg/common/hlog"
 	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
-func RegisterRoutes(h *route.RouterGroup) {
-	// Build box office client from environment.
-	boxClient, _ := boxoffice.NewFromEnv()
+// RegisterRoutes installs the API on h, configured by cfg and backed by db, and starts the background workers under lc.
+func RegisterRoutes(h *route.RouterGroup, cfg *config.Config, lc *lifecycle.Manager, db *sql.DB) error {
+	// Box office client; calls are counted and timed on /metrics. Without an upstream configured,
+	// lookups and enrichment are disabled.
+	var boxClient internal.BoxOfficeClient
+	var upstream internal.UpstreamProber
+	// Tenants with their own credentials get clients with the same circuit breaker.
+	breaker := boxoffice.WithCircuitBreaker(cfg.BoxOffice.BreakerThreshold, cfg.BoxOffice.BreakerCooldown)
+	if cfg.BoxOffice.URL != "" {
+		client, err := boxoffice.NewClient(cfg.BoxOffice.URL, cfg.BoxOffice.APIKey, breaker)
+		if err != nil {
+			return err
+		}
+		boxClient = metrics.InstrumentBoxOffice(client)
+		upstream = client
+	} else {
+		hlog.Warnf("box office upstream not configured; box office lookups and enrichment are disabled")
+	}
+	metrics.MustRegister(collectors.NewDBStatsCollector(db, "movies"), internal.NewStatsCollector(db))
 
-	// Read auth token from environment (for BearerAuth on POST /movies).
-	authToken := os.Getenv("AUTH_TOKEN")
+	opts := []internal.HandlerOption{
+		internal.WithReviewMaxLength(cfg.Reviews.MaxLength),
+		internal.WithReportThreshold(cfg.Reviews.ReportThreshold),
+		internal.WithRaterAuth([]byte(cfg.Raters.TokenSecret), cfg.Raters.AuthMode),
+		internal.WithTenantBoxOfficeOptions(breaker),
+	}
+	if path := cfg.Reviews.Wordlist; path != "" {
+		filter, err := moderation.LoadWordList(path)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, internal.WithContentFilter(filter))
+	}
 
-	handler := internal.NewHandler(internal.DB, boxClient, authToken)
+	// JWT bearer tokens are accepted alongside the static token when a secret or JWKS file is configured.
+	var jwtOpts []jwt.VerifierOption
+	if secret := cfg.Auth.JWTSecret; secret != "" {
+		jwtOpts = append(jwtOpts, jwt.WithHMACSecret([]byte(secret)))
+	}
+	if path := cfg.Auth.JWKSFile; path != "" {
+		keys, err := jwt.LoadJWKS(path)
+		if err != nil {
+			return err
+		}
+		jwtOpts = append(jwtOpts, jwt.WithKeys(keys))
+	}
+	if len(jwtOpts) > 0 {
+		jwtOpts = append(jwtOpts, jwt.WithIssuer(cfg.Auth.JWTIssuer), jwt.WithAudience(cfg.Auth.JWTAudience))
+		opts = append(opts, internal.WithBearerJWT(jwt.NewVerifier(jwtOpts...)))
+	}
+
+	if cfg.TLS.ClientCAFile != "" {
+		opts = append(opts, internal.WithAdminClientCerts())
+	}
+
+	opts = append(opts, internal.WithAPIKeyAuth(cfg.Auth.APIKeys))
+	opts = append(opts, internal.WithRoleDefaults(cfg.Auth.AnonymousRole, cfg.Auth.DefaultRaterRole))
+
+	// Built-in OAuth2 client-credentials server.
+	opts = append(opts, internal.WithOAuthServer([]byte(cfg.OAuth.SigningSecret), cfg.OAuth.Issuer, cfg.OAuth.TokenTTL))
+
+	opts = append(opts, internal.WithRatingRateLimits(
+		ratelimit.New(cfg.RateLimit.RaterPerMinute, cfg.RateLimit.RaterBurst),
+		ratelimit.New(cfg.RateLimit.IPPerMinute, cfg.RateLimit.IPBurst),
+	))
+	opts = append(opts, internal.WithRaterRegistrationLimit(ratelimit.New(cfg.RateLimit.RegistrationPerMinute, cfg.RateLimit.RegistrationBurst)))
+	opts = append(opts, internal.WithBackups(BackupDir(cfg), cfg.Backup.Retention))
+	opts = append(opts, internal.WithHealthChecks(upstream, cfg.Health.CacheTTL), internal.WithDrainCheck(lc.Draining))
+	opts = append(opts, internal.WithIdempotencyTTL(cfg.Idempotency.KeyTTL), internal.WithIdempotencyLease(cfg.Idempotency.Lease))
+	opts = append(opts, internal.WithMaxBodySize(cfg.Server.MaxJSONBodySize))
+	opts = append(opts, internal.WithAbuseDetection(cfg.Abuse.Window, cfg.Abuse.NewRaterThreshold))
+
+	handler := internal.NewHandler(db, boxClient, cfg.Auth.Token, opts...)
 	handler.RegisterRoutes(h)
+
+	lc.Go("abuse detector", func(ctx context.Context) { handler.RunAbuseDetector(ctx, cfg.Abuse.ScanInterval) })
+	lc.Go("enrichment worker", func(ctx context.Context) { handler.RunEnrichmentWorker(ctx, cfg.Enrichment.Interval) })
+	return nil
+}
+
+// BackupDir is cfg.Backup.Dir, defaulting to a backups directory next to the database file.
+func BackupDir(cfg *config.Config) string {
+	if cfg.Backup.Dir != "" {
+		return cfg.Backup.Dir
+	}
+	path, err := internal.DatabasePath(cfg.Database.URL)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "backups")
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"Robin-Camp/internal"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const apiKeyUsage = `用法:
+  apikey create -name <名称> -scopes <权限,...> [-expires <有效期, 如 720h>] [-tenant <租户ID>]
+  apikey list
+  apikey rotate <ID或名称>
+  apikey revoke <ID或名称>
+
+可用权限: `
+
+// runAPIKeyCommand 执行 API Key 管理子命令，返回进程退出码。
+func runAPIKeyCommand(db *sql.DB, args []string) int {
+	usage := apiKeyUsage + strings.Join(internal.KnownScopes, ", ")
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "Key 名称 (唯一)")
+		scopes := fs.String("scopes", "", "逗号分隔的权限列表")
+		expires := fs.Duration("expires", 0, "有效期, 0 表示永不过期")
+		tenant := fs.String("tenant", "", "绑定的租户 ID, 留空则只能访问默认租户")
+		if err := fs.Parse(args[1:]); err != nil {
+			return 2
+		}
+		var expiresAt *time.Time
+		if *expires > 0 {
+			t := time.Now().Add(*expires)
+			expiresAt = &t
+		}
+		var scopeList []string
+		for _, s := range strings.Split(*scopes, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				scopeList = append(scopeList, s)
+			}
+		}
+		k, err := internal.CreateAPIKey(ctx, db, *name, scopeList, expiresAt, *tenant)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "创建失败:", err)
+			return 1
+		}
+		printAPIKeySecret(k)
+	case "list":
+		keys, err := internal.ListAPIKeys(ctx, db, "", -1, 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "查询失败:", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tSCOPES\tTENANT\tCREATED\tLAST USED\tEXPIRES\tREVOKED")
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, strings.Join(k.Scopes, ","), orDash(k.Tenant), k.CreatedAt, orDash(k.LastUsedAt), orDash(k.ExpiresAt), orDash(k.RevokedAt))
+		}
+		w.Flush()
+	case "rotate":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		k, err := internal.RotateAPIKey(ctx, db, args[1], "")
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "轮换失败:", err)
+			return 1
+		}
+		printAPIKeySecret(k)
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, usage)
+			return 2
+		}
+		if err := internal.RevokeAPIKey(ctx, db, args[1], ""); err != nil {
+			fmt.Fprintln(os.Stderr, "吊销失败:", err)
+			return 1
+		}
+		fmt.Println("已吊销", args[1])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		return 2
+	}
+	return 0
+}
+
+func printAPIKeySecret(k *internal.APIKeySecret) {
+	fmt.Printf("ID:      %s\nName:    %s\nScopes:  %s\nTenant:  %s\nExpires: %s\nKey:     %s\n\n请妥善保存该 Key，之后无法再次查看。\n",
+		k.ID, k.Name, strings.Join(k.Scopes, ","), orDash(k.Tenant), orDash(k.ExpiresAt), k.Key)
+}
+
+func orDash(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"Robin-Camp/internal/config"
+	"flag"
+	"fmt"
+	"os"
+)
+
+const configUsage = `用法:
+  config print [-config <配置文件>]
+
+按 默认值 < 配置文件 < 环境变量 (含 .env) 的优先级合并并校验配置, 以 YAML 输出生效的配置, 密钥类配置项显示为 [redacted]。
+配置文件默认为 CONFIG_FILE。输出可直接作为配置文件使用 (需重新填写密钥)。`
+
+// runConfigCommand 输出生效的配置，返回进程退出码。
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, configUsage) }
+	file := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML 配置文件")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Load(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "配置无效:", err)
+		return 1
+	}
+	out, err := cfg.YAML()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "输出失败:", err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
@@ -15,41 +15,25 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/movies": {
+        "/admin/api-keys": {
             "get": {
-                "description": "Returns a paginated list of movies, optionally filtered by query, year, genre and cursor.",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Returns key metadata (never the keys themselves), including revoked and expired keys. Tenant-bound callers only see keys bound to their tenant.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "Admin"
                 ],
-                "summary": "List and search movies",
+                "summary": "List API keys",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Search query for movie title substring",
-                        "name": "q",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Release year (YYYY) derived from releaseDate",
-                        "name": "year",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Exact genre filter",
-                        "name": "genre",
-                        "in": "query"
-                    },
                     {
                         "type": "integer",
-                        "description": "Maximum number of items to return (default 20)",
+                        "description": "Maximum number of keys to return (default 50, max 500)",
                         "name": "limit",
                         "in": "query"
                     },
@@ -64,7 +48,25 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal.MoviePage"
+                            "$ref": "#/definitions/internal.APIKeyPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
                         }
                     },
                     "500": {
@@ -81,7 +83,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Creates a new movie and synchronously enriches it with box office data when available.",
+                "description": "Issues a named API key with the given scopes. The plaintext key is only returned in this response; the server stores a SHA-256 hash.",
                 "consumes": [
                     "application/json"
                 ],
@@ -89,17 +91,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Movies"
+                    "Admin"
                 ],
-                "summary": "Create a new movie",
+                "summary": "Create an API key",
                 "parameters": [
                     {
-                        "description": "Movie to create",
-                        "name": "movie",
+                        "description": "Key name, scopes and optional expiry",
+                        "name": "key",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal.MovieCreate"
+                            "$ref": "#/definitions/internal.APIKeyCreate"
                         }
                     }
                 ],
@@ -107,13 +109,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal.Movie"
-                        },
-                        "headers": {
-                            "Location": {
-                                "type": "string",
-                                "description": "Location of the newly created movie resource"
-                            }
+                            "$ref": "#/definitions/internal.APIKeySecret"
                         }
                     },
                     "400": {
@@ -128,8 +124,20 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal.Error"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin or a requested scope, or is bound to another tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
                     "422": {
-                        "description": "Unprocessable entity (validation or invalid JSON)",
+                        "description": "Unknown scope or tenant, global scope for a tenant-bound key, or invalid expiry",
                         "schema": {
                             "$ref": "#/definitions/internal.Error"
                         }
@@ -143,37 +151,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/movies/{title}/rating": {
-            "get": {
-                "description": "Returns the average rating (rounded to one decimal) and count of ratings for the given movie.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/admin/api-keys/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Disables the key immediately. The record is kept so movies it created remain attributable.",
                 "tags": [
-                    "Ratings"
+                    "Admin"
                 ],
-                "summary": "Get rating aggregate for a movie",
+                "summary": "Revoke an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Movie title",
-                        "name": "title",
+                        "description": "Key ID or name",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "204": {
+                        "description": "Key revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/internal.RatingAggregate"
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
                         }
                     },
                     "404": {
-                        "description": "Movie not found or no ratings yet",
+                        "description": "Key not found, already revoked or bound to another tenant",
                         "schema": {
                             "$ref": "#/definitions/internal.Error"
                         }
@@ -187,82 +203,213 @@ const docTemplate = `{
                 }
             }
         },
-        "/movies/{title}/ratings": {
+        "/admin/api-keys/{id}/rotate": {
             "post": {
                 "security": [
                     {
-                        "RaterId": []
+                        "BearerAuth": []
                     }
                 ],
-                "description": "Submits a rating for the given movie title. If the rater has already rated this movie, the rating is updated.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Generates a new secret for the key, keeping its name, scopes and expiry. The old secret stops working immediately.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Ratings"
+                    "Admin"
                 ],
-                "summary": "Submit or update a rating for a movie",
+                "summary": "Rotate an API key",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Movie title",
-                        "name": "title",
+                        "description": "Key ID or name",
+                        "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.APIKeySecret"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "description": "Unique rater identifier",
-                        "name": "X-Rater-Id",
-                        "in": "header",
-                        "required": true
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
                     },
-                    {
-                        "description": "Rating payload (0.5-5.0 in 0.5 steps)",
-                        "name": "rating",
-                        "in": "body",
-                        "required": true,
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin or a scope the key holds)",
                         "schema": {
-                            "$ref": "#/definitions/internal.RatingSubmit"
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Key not found, revoked or bound to another tenant",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
                         }
                     }
+                }
+            }
+        },
+        "/admin/backups": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the backups in the backup directory, newest first.",
+                "produces": [
+                    "application/json"
                 ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List database backups",
                 "responses": {
                     "200": {
-                        "description": "Rating updated",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal.RatingResult"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.BackupInfo"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (token lacks backups:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "Backup directory not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Writes a consistent online snapshot of the SQLite database (VACUUM INTO) to the backup directory as gzip and prunes old backups beyond the retention count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Take a database backup",
+                "responses": {
                     "201": {
-                        "description": "Rating created",
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal.RatingResult"
-                        },
-                        "headers": {
-                            "Location": {
-                                "type": "string",
-                                "description": "Location of the rating resource when created"
-                            }
+                            "$ref": "#/definitions/internal.BackupInfo"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks backups:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "Backup directory not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/flagged-raters": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns raters the abuse detector flagged in the current tenant, newest first. Their ratings are ignored by aggregates requested with trustedOnly=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List flagged raters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.FlaggedRaterPage"
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid body or rating)",
+                        "description": "Bad request",
                         "schema": {
                             "$ref": "#/definitions/internal.Error"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized (missing or invalid X-Rater-Id)",
+                        "description": "Unauthorized",
                         "schema": {
                             "$ref": "#/definitions/internal.Error"
                         }
                     },
-                    "404": {
-                        "description": "Movie not found",
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
                         "schema": {
                             "$ref": "#/definitions/internal.Error"
                         }
@@ -275,97 +422,3160 @@ const docTemplate = `{
                     }
                 }
             }
-        }
+        },
+        "/admin/flagged-raters/{raterId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Removes a false-positive abuse flag in the current tenant so the rater counts toward its trusted aggregates again.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Clear a rater flag",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rater identifier",
+                        "name": "raterId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Flag removed"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Rater is not flagged",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/moderation/items/{movieId}/{raterId}/{decision}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approving makes the rating and its review visible again and protects it from report-based hiding until the review text is edited; rejecting hides it from aggregates and review listings.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Approve or reject a moderated item",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie ID",
+                        "name": "movieId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author of the rating",
+                        "name": "raterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "approve or reject",
+                        "name": "decision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional note recorded as the moderation reason",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ModerationDecision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ModerationItem"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Rating not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/moderation/queue": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns ratings awaiting a moderation decision (or, with status, items in another moderation state), oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List the moderation queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Moderation status: pending (default), approved, rejected or visible",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ModerationPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth-clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns registered clients (never their secrets), including revoked ones. Tenant-bound callers only see clients bound to their tenant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List OAuth clients",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of clients to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthClientPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Registers a client for the client_credentials grant. The client secret is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Register an OAuth client",
+                "parameters": [
+                    {
+                        "description": "Client name and the scopes it may request",
+                        "name": "client",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthClientCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthClientSecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin or a requested scope, or is bound to another tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Name already in use",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Unknown scope or tenant, or global scope for a tenant-bound client",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth-clients/{clientId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Disables the client; access tokens it was issued are rejected from then on.",
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Revoke an OAuth client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client ID or name",
+                        "name": "clientId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Client revoked"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks apikeys:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found, already revoked or bound to another tenant",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/policy": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns each route with its authentication source (public, rater or bearer) and the permission it requires.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Show the route permission matrix",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.RoutePermission"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks roles:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/raters/{raterId}/token": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Signs a token for a rater ID that predates verifiable identities, so existing raters can migrate off plain X-Rater-Id values. From then on compat mode rejects the plain ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Issue a token for an existing rater ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rater identifier",
+                        "name": "raterId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RaterToken"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "Rater tokens are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rating-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns the append-only rating event log (create, update, delete) for a movie and/or a rater, oldest first. At least one of title, movieId or raterId is required.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Page through rating history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Movie ID (also matches events of deleted movies)",
+                        "name": "movieId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rater identifier",
+                        "name": "raterId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RatingEventPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/role-assignments": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns role assignments in the current tenant, optionally filtered by subject kind and subject.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List role assignments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "principal (API key name, JWT sub or OAuth client ID) or rater",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject identifier",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items to return (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RoleAssignmentPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks roles:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/role-assignments/{kind}/{subject}/{role}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Grants a role in the current tenant to a bearer principal (API key name, JWT sub or OAuth client ID) or to a rater. Assigning any role to a rater replaces the default rater role for them in that tenant.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Assign a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "principal or rater",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject identifier",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "viewer, rater, editor, moderator or admin",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Already assigned",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RoleAssignment"
+                        }
+                    },
+                    "201": {
+                        "description": "Assigned",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RoleAssignment"
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown kind or role",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks roles:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Remove a role assignment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "principal or rater",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Subject identifier",
+                        "name": "subject",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Role name",
+                        "name": "role",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Assignment removed"
+                    },
+                    "400": {
+                        "description": "Unknown kind or role",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks roles:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Assignment not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every assignable role with the permissions it grants.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks roles:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Returns every tenant with its host mappings. Box office API keys are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "List tenants",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.Tenant"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks tenants:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/tenants/{tenantId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates the tenant or replaces its name and host mappings. Box office credentials are only changed when present in the body; send empty strings to fall back to the shared credentials.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Create or update a tenant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tenant ID (lowercase letters, digits and dashes)",
+                        "name": "tenantId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tenant settings",
+                        "name": "tenant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal.TenantUpsert"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Tenant"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks tenants:admin or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Host already mapped to another tenant",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid tenant ID or settings",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/movies": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams every movie of the tenant with its box office data as JSON Lines (default) or CSV, ordered by ID. The format comes from the format parameter or the Accept header.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Export"
+                ],
+                "summary": "Export movies",
+                "parameters": [
+                    {
+                        "enum": [
+                            "jsonl",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact genre filter",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Release year (YYYY)",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only movies updated at or after this timestamp (YYYY-MM-DDTHH:MM:SS.sssZ)",
+                        "name": "updatedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One movie per line",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.Movie"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown format",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks movies:read)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/export/ratings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams every rating of the tenant, including reviews and moderation status, as JSON Lines (default) or CSV. The format comes from the format parameter or the Accept header.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "Export"
+                ],
+                "summary": "Export ratings",
+                "parameters": [
+                    {
+                        "enum": [
+                            "jsonl",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rater identifier",
+                        "name": "raterId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only ratings updated at or after this timestamp (YYYY-MM-DDTHH:MM:SS.sssZ)",
+                        "name": "updatedSince",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "One rating per line",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal.RatingExport"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown format",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks ratings:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/livez": {
+            "get": {
+                "description": "Reports that the process is serving requests. It checks no dependencies, so a failing database does not get the process restarted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.HealthReport"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Request, box office, database and per-tenant catalogue metrics in the Prometheus text exposition format.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Prometheus metrics",
+                "responses": {
+                    "200": {
+                        "description": "Prometheus metrics",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks metrics:read or is bound to a tenant)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies": {
+            "get": {
+                "description": "Returns a paginated list of movies, optionally filtered by query, year, genre and cursor.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "List and search movies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query for movie title substring",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Release year (YYYY) derived from releaseDate",
+                        "name": "year",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact genre filter",
+                        "name": "genre",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items to return (default 20)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.MoviePage"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong validator for the page"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Latest update among the movies on the page"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a new movie and synchronously enriches it with box office data when available. A title the tenant already has is rejected with 409 unless onConflict is set: \"return\" answers 200 with the stored movie, \"update\" overwrites its fields and answers 200.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Create a new movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client-generated key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "return",
+                            "update"
+                        ],
+                        "type": "string",
+                        "description": "Behaviour when the title exists",
+                        "name": "onConflict",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the stored movie; the write fails with 412 when it has changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Movie to create",
+                        "name": "movie",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal.MovieCreate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Existing movie returned or updated (onConflict)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Movie"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Movie"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Location of the newly created movie resource"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks movies:write)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Title already exists (details.id and Location identify the stored movie) or an Idempotency-Key request is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match does not match the stored movie",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable entity (validation, invalid JSON, unknown field or Idempotency-Key reused with a different body)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/trending": {
+            "get": {
+                "description": "Ranks movies by recent rating activity and score momentum. Each rating inside the window is weighted by an exponential decay on its age (half-life of half the window); momentum is the weighted recent average minus the all-time average. Results are cached briefly.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "List trending movies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Look-back window, e.g. 24h or 7d (default 7d, max 90d)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items to return (default 10, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.TrendingPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid window or limit",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}": {
+            "get": {
+                "description": "Returns a single movie with its box office data.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Get a movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Movie"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong validator, usable with If-Match on POST /movies?onConflict=update"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the last change to the movie"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "404": {
+                        "description": "Movie not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}/boxoffice/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Re-fetches box office figures from the upstream API and replaces the stored values.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Refresh box office data for a movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.BoxOffice"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks boxoffice:admin)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie or box office data not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "502": {
+                        "description": "Upstream box office API failed",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}/rating": {
+            "get": {
+                "description": "Returns the average rating (rounded to one decimal) and count of ratings for the given movie. Ratings hidden by moderation are excluded.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ratings"
+                ],
+                "summary": "Get rating aggregate for a movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Ignore ratings from raters flagged by abuse detection",
+                        "name": "trustedOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag from a previous response",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last-Modified from a previous response",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RatingAggregate"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Strong validator for the aggregate"
+                            },
+                            "Last-Modified": {
+                                "type": "string",
+                                "description": "Time of the latest rating change for the movie"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "404": {
+                        "description": "Movie not found or no ratings yet",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}/ratings": {
+            "post": {
+                "security": [
+                    {
+                        "RaterId": []
+                    }
+                ],
+                "description": "Submits a rating for the given movie title. If the rater has already rated this movie, the rating is updated. An attached review replaces the previous one; omitting it keeps the stored review.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ratings"
+                ],
+                "summary": "Submit or update a rating for a movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rater identifier, signed rater token or rater JWT",
+                        "name": "X-Rater-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client-generated key; retries with the same key and body replay the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the caller's current rating; the update fails with 412 when it has changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Rating payload (0.5-5.0 in 0.5 steps) with an optional review",
+                        "name": "rating",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal.RatingSubmit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rating updated",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RatingResult"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the stored rating"
+                            }
+                        }
+                    },
+                    "201": {
+                        "description": "Rating created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RatingResult"
+                        },
+                        "headers": {
+                            "ETag": {
+                                "type": "string",
+                                "description": "Version of the stored rating"
+                            },
+                            "Location": {
+                                "type": "string",
+                                "description": "Location of the rating resource when created"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (invalid body, unknown field or rating)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (missing or invalid X-Rater-Id)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "A request with the same Idempotency-Key is still in progress",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match does not match the caller's current rating",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Rating out of range, invalid review or Idempotency-Key reused with a different body",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded for this rater or client IP",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "RaterId": []
+                    }
+                ],
+                "description": "Removes the rating submitted by the X-Rater-Id caller. The deletion is recorded in the rating event history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Ratings"
+                ],
+                "summary": "Delete the caller's rating for a movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Rater identifier, signed rater token or rater JWT",
+                        "name": "X-Rater-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the caller's current rating; the delete fails with 412 when it has changed",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Rating deleted"
+                    },
+                    "401": {
+                        "description": "Unauthorized (missing or invalid X-Rater-Id)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie or rating not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "412": {
+                        "description": "If-Match does not match the caller's current rating",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}/ratings/{raterId}/reports": {
+            "post": {
+                "security": [
+                    {
+                        "RaterId": []
+                    }
+                ],
+                "description": "Flags another rater's rating (and its review) for moderation. Each rater can report an item once; once the number of distinct reports reaches the configured threshold the item is hidden until an admin decides.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Moderation"
+                ],
+                "summary": "Report a rating or review",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author of the reported rating",
+                        "name": "raterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique rater identifier of the reporter",
+                        "name": "X-Rater-Id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Optional reason",
+                        "name": "report",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ReportSubmit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ReportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (missing or invalid X-Rater-Id)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie or rating not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Cannot report own rating",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded for this rater or client IP",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}/reviews": {
+            "get": {
+                "description": "Returns the text reviews attached to ratings of the given movie, sorted by helpfulness (default) or recency. Reviews hidden by moderation are omitted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "List reviews for a movie",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: helpful (default) or recent",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of items to return (default 20, max 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Pagination cursor from previous page's nextCursor",
+                        "name": "cursor",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ReviewPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies/{title}/reviews/{raterId}/helpful": {
+            "post": {
+                "security": [
+                    {
+                        "RaterId": []
+                    }
+                ],
+                "description": "Records that the X-Rater-Id caller found the review helpful. Repeated votes are ignored; raters cannot vote on their own review.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reviews"
+                ],
+                "summary": "Mark a review as helpful",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Movie title",
+                        "name": "title",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Author of the review",
+                        "name": "raterId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unique rater identifier of the voter",
+                        "name": "X-Rater-Id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.HelpfulVoteResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (missing or invalid X-Rater-Id)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Movie or review not found",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Cannot vote on own review",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded for this rater or client IP",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/movies:batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Streams MovieCreate records as JSON Lines (default) or CSV with a header row, validates each row and inserts them in chunked transactions. Existing titles are skipped unless onConflict=update. Box office enrichment is queued instead of running inline. The response reports the outcome of every row; invalid rows do not abort the import.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Movies"
+                ],
+                "summary": "Bulk import movies",
+                "parameters": [
+                    {
+                        "enum": [
+                            "jsonl",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Input format; defaults from Content-Type (text/csv selects CSV)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "skip",
+                            "update"
+                        ],
+                        "type": "string",
+                        "description": "Behaviour for titles that already exist (default skip)",
+                        "name": "onConflict",
+                        "in": "query"
+                    },
+                    {
+                        "description": "JSON Lines or CSV records",
+                        "name": "records",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (unknown format or onConflict)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (token lacks movies:write)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "CSV header lacks required columns or a JSON Lines record is too long",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "RFC 7662 token introspection for tokens issued by this server. Callers authenticate as a registered client. Unknown, expired and revoked tokens report active=false.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Introspect an access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthIntrospection"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_client",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthError"
+                        }
+                    },
+                    "501": {
+                        "description": "OAuth server is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "RFC 7009 token revocation. A client can only revoke its own tokens; unknown or foreign tokens are ignored and still return 200.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Revoke an access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token revoked or ignored"
+                    },
+                    "400": {
+                        "description": "invalid_request",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_client",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthError"
+                        }
+                    },
+                    "501": {
+                        "description": "OAuth server is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "OAuth 2.0 token endpoint for the client_credentials grant. Authenticate with HTTP Basic or client_id/client_secret form fields. The returned JWT is accepted as a Bearer token on protected routes.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "OAuth"
+                ],
+                "summary": "Issue an access token (client credentials)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be client_credentials",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space-separated subset of the client's scopes (default: all)",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID when not using HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret when not using HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthToken"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid_request, unsupported_grant_type or invalid_scope",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthError"
+                        }
+                    },
+                    "401": {
+                        "description": "invalid_client",
+                        "schema": {
+                            "$ref": "#/definitions/internal.OAuthError"
+                        }
+                    },
+                    "501": {
+                        "description": "OAuth server is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/raters": {
+            "post": {
+                "description": "Issues a new random rater ID together with a signed token. Send the token as X-Rater-Id on rating endpoints; the server derives the rater ID from it. Registrations are rate limited per client IP.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Raters"
+                ],
+                "summary": "Register a rater identity",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal.RaterToken"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many registrations from this client IP",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    },
+                    "501": {
+                        "description": "Rater tokens are not configured",
+                        "schema": {
+                            "$ref": "#/definitions/internal.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Reports each dependency: database ping and write probe, schema migration version, box office upstream reachability and the circuit state of the shared and per-tenant clients, and the enrichment queue backlog.\nResults are cached for a few seconds. Upstream or queue problems report degraded and keep the service ready; a database or migration failure, or a shutdown in progress, reports down with 503.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "Ready (ok or degraded)",
+                        "schema": {
+                            "$ref": "#/definitions/internal.HealthReport"
+                        }
+                    },
+                    "503": {
+                        "description": "Not ready",
+                        "schema": {
+                            "$ref": "#/definitions/internal.HealthReport"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "internal.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.APIKeyCreate": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "description": "ExpiresAt is an optional RFC 3339 timestamp after which the key stops working.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "description": "Tenant binds the key to one tenant; unbound keys only act on the default tenant.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal.APIKeyPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.APIKey"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.APIKeySecret": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.BackupInfo": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "schemaVersion": {
+                    "description": "SchemaVersion is only reported for the backup just taken.",
+                    "type": "integer"
+                },
+                "sizeBytes": {
+                    "type": "integer"
+                }
+            }
+        },
         "internal.BoxOffice": {
             "type": "object",
             "properties": {
-                "currency": {
+                "currency": {
+                    "type": "string"
+                },
+                "lastUpdated": {
+                    "type": "string"
+                },
+                "revenue": {
+                    "$ref": "#/definitions/internal.Revenue"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.Error": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "details": {},
+                "message": {
+                    "type": "string"
+                },
+                "requestId": {
+                    "description": "RequestID matches the X-Request-Id response header and the request_id of the server's log records.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal.FlaggedRater": {
+            "type": "object",
+            "properties": {
+                "flaggedAt": {
+                    "type": "string"
+                },
+                "movieId": {
+                    "type": "string"
+                },
+                "raterId": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.FlaggedRaterPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.FlaggedRater"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.HealthCheck": {
+            "type": "object",
+            "properties": {
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latencyMs": {
+                    "type": "number",
+                    "example": 0.4
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "internal.HealthReport": {
+            "type": "object",
+            "properties": {
+                "checkedAt": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/internal.HealthCheck"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "internal.HelpfulVoteResult": {
+            "type": "object",
+            "properties": {
+                "helpfulCount": {
+                    "type": "integer"
+                },
+                "raterId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.ImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.ImportRow"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.ImportRow": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "created",
+                        "updated",
+                        "skipped",
+                        "failed"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.ModerationDecision": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.ModerationItem": {
+            "type": "object",
+            "properties": {
+                "movieId": {
+                    "type": "string"
+                },
+                "movieTitle": {
+                    "type": "string"
+                },
+                "raterId": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reportCount": {
+                    "type": "integer"
+                },
+                "reviewBody": {
+                    "type": "string"
+                },
+                "reviewTitle": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.ModerationPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.ModerationItem"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.Movie": {
+            "type": "object",
+            "properties": {
+                "boxOffice": {
+                    "$ref": "#/definitions/internal.BoxOffice"
+                },
+                "budget": {
+                    "type": "integer"
+                },
+                "createdBy": {
+                    "type": "string"
+                },
+                "distributor": {
+                    "type": "string"
+                },
+                "genre": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "mpaRating": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.MovieCreate": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "type": "integer"
+                },
+                "distributor": {
+                    "type": "string"
+                },
+                "genre": {
+                    "type": "string"
+                },
+                "mpaRating": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.MoviePage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.Movie"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthClient": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthClientCreate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "description": "Tenant binds the client and the tokens it obtains to one tenant; unbound clients only act on the default tenant.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthClientPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.OAuthClient"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthClientSecret": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "type": "string"
+                },
+                "clientSecret": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "revokedAt": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "error_description": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthIntrospection": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "string"
+                },
+                "exp": {
+                    "type": "integer"
+                },
+                "iat": {
+                    "type": "integer"
+                },
+                "iss": {
+                    "type": "string"
+                },
+                "jti": {
+                    "type": "string"
+                },
+                "scope": {
+                    "type": "string"
+                },
+                "sub": {
+                    "type": "string"
+                },
+                "token_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.OAuthToken": {
+            "type": "object",
+            "properties": {
+                "access_token": {
                     "type": "string"
                 },
-                "lastUpdated": {
-                    "type": "string"
+                "expires_in": {
+                    "type": "integer"
                 },
-                "revenue": {
-                    "$ref": "#/definitions/internal.Revenue"
+                "scope": {
+                    "type": "string"
                 },
-                "source": {
+                "token_type": {
                     "type": "string"
                 }
             }
         },
-        "internal.Error": {
+        "internal.RaterToken": {
             "type": "object",
             "properties": {
-                "code": {
+                "raterId": {
                     "type": "string"
                 },
-                "details": {},
-                "message": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "internal.Movie": {
+        "internal.RatingAggregate": {
             "type": "object",
             "properties": {
-                "boxOffice": {
-                    "$ref": "#/definitions/internal.BoxOffice"
+                "average": {
+                    "type": "number"
                 },
-                "budget": {
+                "count": {
                     "type": "integer"
+                }
+            }
+        },
+        "internal.RatingEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
                 },
-                "distributor": {
+                "clientIp": {
                     "type": "string"
                 },
-                "genre": {
+                "createdAt": {
                     "type": "string"
                 },
                 "id": {
+                    "type": "integer"
+                },
+                "movieId": {
                     "type": "string"
                 },
-                "mpaRating": {
+                "movieTitle": {
                     "type": "string"
                 },
-                "releaseDate": {
+                "newRating": {
+                    "type": "number"
+                },
+                "oldRating": {
+                    "type": "number"
+                },
+                "raterId": {
                     "type": "string"
+                }
+            }
+        },
+        "internal.RatingEventPage": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.RatingEvent"
+                    }
                 },
-                "title": {
+                "nextCursor": {
                     "type": "string"
                 }
             }
         },
-        "internal.MovieCreate": {
+        "internal.RatingExport": {
             "type": "object",
             "properties": {
-                "budget": {
+                "moderationStatus": {
+                    "type": "string"
+                },
+                "movieId": {
+                    "type": "string"
+                },
+                "movieTitle": {
+                    "type": "string"
+                },
+                "raterId": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "reviewBody": {
+                    "type": "string"
+                },
+                "reviewLanguage": {
+                    "type": "string"
+                },
+                "reviewSpoiler": {
+                    "type": "boolean"
+                },
+                "reviewTitle": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.RatingResult": {
+            "type": "object",
+            "properties": {
+                "movieTitle": {
+                    "type": "string"
+                },
+                "raterId": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "review": {
+                    "$ref": "#/definitions/internal.ReviewSubmit"
+                }
+            }
+        },
+        "internal.RatingSubmit": {
+            "type": "object",
+            "properties": {
+                "rating": {
+                    "type": "number"
+                },
+                "review": {
+                    "$ref": "#/definitions/internal.ReviewSubmit"
+                }
+            }
+        },
+        "internal.ReportResult": {
+            "type": "object",
+            "properties": {
+                "raterId": {
+                    "type": "string"
+                },
+                "reportCount": {
                     "type": "integer"
                 },
-                "distributor": {
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.ReportSubmit": {
+            "type": "object",
+            "properties": {
+                "reason": {
                     "type": "string"
+                }
+            }
+        },
+        "internal.Revenue": {
+            "type": "object",
+            "properties": {
+                "openingWeekendUSA": {
+                    "type": "integer"
                 },
-                "genre": {
+                "worldwide": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal.Review": {
+            "type": "object",
+            "properties": {
+                "body": {
                     "type": "string"
                 },
-                "mpaRating": {
+                "helpfulCount": {
+                    "type": "integer"
+                },
+                "language": {
                     "type": "string"
                 },
-                "releaseDate": {
+                "raterId": {
                     "type": "string"
                 },
+                "rating": {
+                    "type": "number"
+                },
+                "spoiler": {
+                    "type": "boolean"
+                },
                 "title": {
                     "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "internal.MoviePage": {
+        "internal.ReviewPage": {
             "type": "object",
             "properties": {
                 "items": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal.Movie"
+                        "$ref": "#/definitions/internal.Review"
                     }
                 },
                 "nextCursor": {
@@ -373,47 +3583,169 @@ const docTemplate = `{
                 }
             }
         },
-        "internal.RatingAggregate": {
+        "internal.ReviewSubmit": {
             "type": "object",
             "properties": {
-                "average": {
-                    "type": "number"
+                "body": {
+                    "type": "string"
                 },
-                "count": {
-                    "type": "integer"
+                "language": {
+                    "type": "string"
+                },
+                "spoiler": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
                 }
             }
         },
-        "internal.RatingResult": {
+        "internal.Role": {
             "type": "object",
             "properties": {
-                "movieTitle": {
+                "name": {
                     "type": "string"
                 },
-                "raterId": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal.RoleAssignment": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
                     "type": "string"
                 },
-                "rating": {
-                    "type": "number"
+                "role": {
+                    "type": "string"
+                },
+                "subject": {
+                    "type": "string"
+                },
+                "subjectKind": {
+                    "type": "string"
                 }
             }
         },
-        "internal.RatingSubmit": {
+        "internal.RoleAssignmentPage": {
             "type": "object",
             "properties": {
-                "rating": {
-                    "type": "number"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.RoleAssignment"
+                    }
+                },
+                "nextCursor": {
+                    "type": "string"
                 }
             }
         },
-        "internal.Revenue": {
+        "internal.RoutePermission": {
             "type": "object",
             "properties": {
-                "openingWeekendUSA": {
-                    "type": "integer"
+                "auth": {
+                    "type": "string"
                 },
-                "worldwide": {
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.Tenant": {
+            "type": "object",
+            "properties": {
+                "boxOfficeUrl": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "hasBoxOfficeApiKey": {
+                    "type": "boolean"
+                },
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.TenantUpsert": {
+            "type": "object",
+            "properties": {
+                "boxOfficeApiKey": {
+                    "type": "string"
+                },
+                "boxOfficeUrl": {
+                    "description": "BoxOfficeURL and BoxOfficeAPIKey override the deployment-wide box office credentials for this tenant.",
+                    "type": "string"
+                },
+                "hosts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.TrendingMovie": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "momentum": {
+                    "type": "number"
+                },
+                "recentAverage": {
+                    "type": "number"
+                },
+                "recentCount": {
                     "type": "integer"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal.TrendingPage": {
+            "type": "object",
+            "properties": {
+                "generatedAt": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal.TrendingMovie"
+                    }
+                },
+                "window": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"Robin-Camp/internal"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+const importUsage = `用法:
+  import -file <路径, - 表示标准输入> [-format jsonl|csv] [-tenant <租户ID>] [-update] [-report <报告文件>]
+
+格式默认按扩展名判断 (.csv 为 CSV, 其余为 JSON Lines)。已存在的片名默认跳过, -update 时覆盖。
+新建影片的票房数据由服务运行时的后台任务补全。`
+
+// runImportCommand 批量导入影片，返回进程退出码。存在失败行时返回 1。
+func runImportCommand(db *sql.DB, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, importUsage) }
+	file := fs.String("file", "", "输入文件路径, - 表示标准输入")
+	format := fs.String("format", "", "输入格式 jsonl 或 csv")
+	tenant := fs.String("tenant", internal.DefaultTenant, "导入到的租户 ID")
+	update := fs.Bool("update", false, "覆盖已存在的影片")
+	reportPath := fs.String("report", "", "将逐行报告以 JSON 写入该文件")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, importUsage)
+		return 2
+	}
+
+	var in io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "打开文件失败:", err)
+			return 1
+		}
+		defer f.Close()
+		in = f
+		if *format == "" && strings.EqualFold(filepath.Ext(*file), ".csv") {
+			*format = internal.ImportFormatCSV
+		}
+	}
+
+	report, err := internal.ImportMovies(context.Background(), db, in, internal.ImportOptions{
+		TenantID: *tenant,
+		Format:   *format,
+		Update:   *update,
+	})
+	if report != nil {
+		printImportReport(report)
+		if *reportPath != "" {
+			if werr := writeImportReport(*reportPath, report); werr != nil {
+				fmt.Fprintln(os.Stderr, "写入报告失败:", werr)
+				return 1
+			}
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "导入失败:", err)
+		return 1
+	}
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func printImportReport(report *internal.ImportReport) {
+	fmt.Printf("新建 %d, 更新 %d, 跳过 %d, 失败 %d\n", report.Created, report.Updated, report.Skipped, report.Failed)
+	if report.Skipped+report.Failed == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "LINE\tTITLE\tSTATUS\tREASON")
+	for _, row := range report.Rows {
+		if row.Status == internal.ImportSkipped || row.Status == internal.ImportFailed {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", row.Line, row.Title, row.Status, row.Reason)
+		}
+	}
+	w.Flush()
+}
+
+func writeImportReport(path string, report *internal.ImportReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+const (
+	defaultAbuseWindow    = 10 * time.Minute
+	defaultAbuseThreshold = 20
+)
+
+// rateLimitRatings enforces the per-rater and per-IP token buckets on rating submission, reports and helpful votes,
+// which share one budget. Rater IDs are cheap to come by, so the per-IP bucket is what bounds a flood of them.
+func (h *Handler) rateLimitRatings(next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if ok, wait := h.raterLimiter.Allow(currentRater(c)); !ok {
+			tooManyRequests(c, wait, "too many rating requests, retry later")
+			return
+		}
+		if ok, wait := h.ipLimiter.Allow(c.ClientIP()); !ok {
+			tooManyRequests(c, wait, "too many rating requests, retry later")
+			return
+		}
+		next(ctx, c)
+	}
+}
+
+// rateLimitRegistrations enforces the per-IP token bucket on rater registration, which needs no credentials.
+func (h *Handler) rateLimitRegistrations(next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if ok, wait := h.registrationLimiter.Allow(c.ClientIP()); !ok {
+			tooManyRequests(c, wait, "too many rater registrations, retry later")
+			return
+		}
+		next(ctx, c)
+	}
+}
+
+func tooManyRequests(c *app.RequestContext, wait time.Duration, message string) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	writeError(c, http.StatusTooManyRequests, Error{Code: "RATE_LIMITED", Message: message})
+}
+
+// RunAbuseDetector periodically flags bursts of first-time raters hitting a single movie until ctx is cancelled.
+func (h *Handler) RunAbuseDetector(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, interval)
+			n, err := detectNewRaterBursts(runCtx, h.db, time.Now().UTC(), h.abuseWindow, h.abuseThreshold)
+			cancel()
+			if err != nil {
+				hlog.Errorf("abuse detector: %v", err)
+			} else if n > 0 {
+				hlog.Warnf("abuse detector: flagged %d raters", n)
+			}
+		}
+	}
+}
+
+// detectNewRaterBursts flags every rater whose first-ever rating falls inside the window when
+// at least threshold such raters hit the same movie. The flag belongs to the movie's tenant.
+// It returns the number of newly flagged raters.
+func detectNewRaterBursts(ctx context.Context, db *sql.DB, now time.Time, window time.Duration, threshold int) (int, error) {
+	cutoff := now.Add(-window).Format(sqliteTimeLayout)
+	rows, err := db.QueryContext(ctx, `SELECT e.movie_id, m.tenant_id, e.rater_id FROM rating_events e JOIN movies m ON m.id = e.movie_id WHERE e.action = 'create' AND e.created_at >= ? AND NOT EXISTS (SELECT 1 FROM rating_events p WHERE p.rater_id = e.rater_id AND p.created_at < ?)`, cutoff, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("scan rating events: %w", err)
+	}
+	byMovie := make(map[string]map[string]struct{})
+	tenants := make(map[string]string)
+	for rows.Next() {
+		var movieID, tenantID, raterID string
+		if err := rows.Scan(&movieID, &tenantID, &raterID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if byMovie[movieID] == nil {
+			byMovie[movieID] = make(map[string]struct{})
+		}
+		byMovie[movieID][raterID] = struct{}{}
+		tenants[movieID] = tenantID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	flagged := 0
+	err = WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		for movieID, raters := range byMovie {
+			if len(raters) < threshold {
+				continue
+			}
+			tenantID := tenants[movieID]
+			reason := fmt.Sprintf("%d new raters within %s", len(raters), window)
+			for raterID := range raters {
+				res, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO flagged_raters (tenant_id, rater_id, movie_id, reason) VALUES (?, ?, ?, ?)`, tenantID, raterID, movieID, reason)
+				if err != nil {
+					return fmt.Errorf("flag rater: %w", err)
+				}
+				if n, err := res.RowsAffected(); err == nil && n > 0 {
+					flagged += int(n)
+					if err := touchRaterRatings(ctx, tx, tenantID, raterID); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+	return flagged, err
+}
+
+// listFlaggedRaters godoc
+// @Summary      List flagged raters
+// @Description  Returns raters the abuse detector flagged in the current tenant, newest first. Their ratings are ignored by aggregates requested with trustedOnly=true.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit   query     int     false  "Maximum number of items to return (default 50, max 500)"
+// @Param        cursor  query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200     {object}  FlaggedRaterPage
+// @Failure      400     {object}  Error  "Bad request"
+// @Failure      401     {object}  Error  "Unauthorized"
+// @Failure      403     {object}  Error  "Forbidden (token lacks ratings:admin)"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /admin/flagged-raters [get]
+func (h *Handler) listFlaggedRaters(ctx context.Context, c *app.RequestContext) {
+	limit := defaultEventLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxEventLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+	offset := 0
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.Atoi(cursorStr)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		offset = v
+	}
+
+	rows, err := h.db.QueryContext(ctx, `SELECT rater_id, movie_id, reason, flagged_at FROM flagged_raters WHERE tenant_id = ? ORDER BY flagged_at DESC, rater_id LIMIT ? OFFSET ?`, currentTenant(c), limit+1, offset)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	defer rows.Close()
+
+	items := []FlaggedRater{}
+	for rows.Next() {
+		var f FlaggedRater
+		if err := rows.Scan(&f.RaterID, &f.MovieID, &f.Reason, &f.FlaggedAt); err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		items = append(items, f)
+	}
+	if err := rows.Err(); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	var nextCursor *string
+	if len(items) > limit {
+		items = items[:limit]
+		next := strconv.Itoa(offset + limit)
+		nextCursor = &next
+	}
+	c.JSON(http.StatusOK, FlaggedRaterPage{Items: items, NextCursor: nextCursor})
+}
+
+// unflagRater godoc
+// @Summary      Clear a rater flag
+// @Description  Removes a false-positive abuse flag in the current tenant so the rater counts toward its trusted aggregates again.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Param        raterId  path  string  true  "Rater identifier"
+// @Success      204      "Flag removed"
+// @Failure      401      {object}  Error  "Unauthorized"
+// @Failure      403      {object}  Error  "Forbidden (token lacks ratings:admin)"
+// @Failure      404      {object}  Error  "Rater is not flagged"
+// @Failure      500      {object}  Error  "Internal server error"
+// @Router       /admin/flagged-raters/{raterId} [delete]
+func (h *Handler) unflagRater(ctx context.Context, c *app.RequestContext) {
+	tenantID, raterID := currentTenant(c), c.Param("raterId")
+	err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM flagged_raters WHERE tenant_id = ? AND rater_id = ?`, tenantID, raterID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err == nil && n == 0 {
+			return errRaterNotFlagged
+		}
+		return touchRaterRatings(ctx, tx, tenantID, raterID)
+	})
+	if err != nil {
+		if errors.Is(err, errRaterNotFlagged) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "rater is not flagged"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// errRaterNotFlagged signals that unflagRater found no flag to clear.
+var errRaterNotFlagged = errors.New("rater is not flagged")
+
+// touchRaterRatings stamps moderated_at on every rating by the rater in the tenant. Flagging or clearing a rater
+// changes the trusted aggregates of each movie they rated there, whose Last-Modified is derived from it.
+func touchRaterRatings(ctx context.Context, tx *sql.Tx, tenantID, raterID string) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE ratings SET moderated_at = STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE tenant_id = ? AND rater_id = ?`, tenantID, raterID); err != nil {
+		return fmt.Errorf("touch rater ratings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,406 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// apiKeyPrefix marks keys issued by this service; the full key is "rck_<id>_<secret>".
+const apiKeyPrefix = "rck_"
+
+// apiKeyLastUsedResolution is how stale last_used_at may get before a successful authentication updates it.
+const apiKeyLastUsedResolution = time.Minute
+
+var (
+	// ErrAPIKeyNotFound is returned when no key matches the given ID or name.
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrAPIKeyNameTaken is returned when a key with the same name already exists.
+	ErrAPIKeyNameTaken = errors.New("api key name already in use")
+	// ErrUnknownScope is returned when a key is requested with a scope the service does not define.
+	ErrUnknownScope = errors.New("unknown scope")
+	// ErrUnknownTenant is returned when a credential is bound to a tenant that does not exist.
+	ErrUnknownTenant = errors.New("unknown tenant")
+	// ErrGlobalScope is returned when a tenant-bound credential is requested with a scope that acts on every tenant.
+	ErrGlobalScope = errors.New("scope cannot be granted to a tenant-bound credential")
+)
+
+// KnownScopes lists every scope that can be granted to an API key.
+var KnownScopes = []string{
+	ScopeMoviesRead, ScopeMoviesWrite, ScopeRatingsRead, ScopeRatingsWrite,
+	ScopeRatingsAdmin, ScopeBoxOfficeAdmin, ScopeAPIKeysAdmin, ScopeRolesAdmin, ScopeTenantsAdmin,
+	ScopeBackupsAdmin, ScopeMetricsRead,
+}
+
+// globalScopes administer the whole deployment rather than one tenant's data, so tenant-bound credentials
+// cannot hold them.
+var globalScopes = []string{ScopeRolesAdmin, ScopeTenantsAdmin, ScopeBackupsAdmin, ScopeMetricsRead}
+
+// checkScopes validates the scopes requested for a new credential bound to tenant ("" for unbound).
+func checkScopes(scopes []string, tenant string) error {
+	for _, s := range scopes {
+		if !slices.Contains(KnownScopes, s) {
+			return fmt.Errorf("%w: %s", ErrUnknownScope, s)
+		}
+		if tenant != "" && slices.Contains(globalScopes, s) {
+			return fmt.Errorf("%w: %s", ErrGlobalScope, s)
+		}
+	}
+	return nil
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKeySecret(id string) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CreateAPIKey stores a new key and returns its metadata together with the plaintext key, which is not retrievable later.
+// A non-empty tenant binds the key to that tenant, which rules out the global scopes.
+func CreateAPIKey(ctx context.Context, db *sql.DB, name string, scopes []string, expiresAt *time.Time, tenant string) (*APIKeySecret, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := checkScopes(scopes, tenant); err != nil {
+		return nil, err
+	}
+
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+	id := hex.EncodeToString(idBytes)
+	key, err := newAPIKeySecret(id)
+	if err != nil {
+		return nil, err
+	}
+	var expires *string
+	if expiresAt != nil {
+		v := expiresAt.UTC().Format(sqliteTimeLayout)
+		expires = &v
+	}
+
+	err = WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM api_keys WHERE name = ?)`, name).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrAPIKeyNameTaken
+		}
+		if err := checkTenantExists(ctx, tx, tenant); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO api_keys (id, name, key_hash, scopes, expires_at, tenant_id) VALUES (?, ?, ?, ?, ?, ?)`,
+			id, name, hashAPIKey(key), strings.Join(scopes, " "), expires, nullIfEmpty(tenant),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := getAPIKey(ctx, db, id, "")
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeySecret{APIKey: *k, Key: key}, nil
+}
+
+// ListAPIKeys returns keys ordered by creation time, including revoked and expired ones. A non-empty tenant
+// restricts the list to keys bound to it.
+func ListAPIKeys(ctx context.Context, db *sql.DB, tenant string, limit, offset int) ([]APIKey, error) {
+	rows, err := db.QueryContext(ctx, `SELECT id, name, scopes, tenant_id, created_at, last_used_at, expires_at, revoked_at FROM api_keys
+		WHERE (? = '' OR tenant_id = ?) ORDER BY created_at, id LIMIT ? OFFSET ?`, tenant, tenant, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *k)
+	}
+	return res, rows.Err()
+}
+
+// RotateAPIKey replaces the secret of the key identified by ID or name; the previous secret stops working immediately.
+// A non-empty tenant only matches keys bound to it, so keys of other tenants are reported as not found.
+func RotateAPIKey(ctx context.Context, db *sql.DB, ref, tenant string) (*APIKeySecret, error) {
+	k, err := getAPIKey(ctx, db, ref, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if k.RevokedAt != nil {
+		return nil, ErrAPIKeyNotFound
+	}
+	key, err := newAPIKeySecret(k.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE api_keys SET key_hash = ?, last_used_at = NULL WHERE id = ?`, hashAPIKey(key), k.ID); err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+	k.LastUsedAt = nil
+	return &APIKeySecret{APIKey: *k, Key: key}, nil
+}
+
+// RevokeAPIKey disables the key identified by ID or name. Revoked keys stay listed for auditing.
+// A non-empty tenant only matches keys bound to it.
+func RevokeAPIKey(ctx context.Context, db *sql.DB, ref, tenant string) error {
+	res, err := db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')
+		WHERE (id = ? OR name = ?) AND (? = '' OR tenant_id = ?) AND revoked_at IS NULL`, ref, ref, tenant, tenant)
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(row rowScanner) (*APIKey, error) {
+	var k APIKey
+	var scopes string
+	if err := row.Scan(&k.ID, &k.Name, &scopes, &k.Tenant, &k.CreatedAt, &k.LastUsedAt, &k.ExpiresAt, &k.RevokedAt); err != nil {
+		return nil, err
+	}
+	k.Scopes = strings.Fields(scopes)
+	return &k, nil
+}
+
+func getAPIKey(ctx context.Context, db *sql.DB, ref, tenant string) (*APIKey, error) {
+	k, err := scanAPIKey(db.QueryRowContext(ctx, `SELECT id, name, scopes, tenant_id, created_at, last_used_at, expires_at, revoked_at FROM api_keys
+		WHERE (id = ? OR name = ?) AND (? = '' OR tenant_id = ?)`, ref, ref, tenant, tenant))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return k, err
+}
+
+// authenticateAPIKey resolves a "rck_" key to a principal named after the key, or nil when it is unknown,
+// revoked or expired. The stored hash is compared in constant time.
+func (h *Handler) authenticateAPIKey(ctx context.Context, token string) *Principal {
+	rest, ok := strings.CutPrefix(token, apiKeyPrefix)
+	if !ok {
+		return nil
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return nil
+	}
+
+	var name, hash, scopes string
+	var tenant sql.NullString
+	var lastUsedAt, expiresAt, revokedAt *string
+	if err := h.db.QueryRowContext(ctx, `SELECT name, key_hash, scopes, tenant_id, last_used_at, expires_at, revoked_at FROM api_keys WHERE id = ?`, id).
+		Scan(&name, &hash, &scopes, &tenant, &lastUsedAt, &expiresAt, &revokedAt); err != nil {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(token)), []byte(hash)) != 1 {
+		return nil
+	}
+	now := time.Now().UTC().Format(sqliteTimeLayout)
+	if revokedAt != nil || (expiresAt != nil && *expiresAt <= now) {
+		return nil
+	}
+
+	// last_used_at only needs minute precision; skipping fresher updates keeps key use from writing on every request.
+	stale := time.Now().UTC().Add(-apiKeyLastUsedResolution).Format(sqliteTimeLayout)
+	if lastUsedAt == nil || *lastUsedAt < stale {
+		if _, err := h.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = ? WHERE id = ?`, now, id); err != nil {
+			return nil
+		}
+	}
+	return &Principal{Subject: name, Scopes: strings.Fields(scopes), Tenant: tenant.String}
+}
+
+// createAPIKey godoc
+// @Summary      Create an API key
+// @Description  Issues a named API key with the given scopes. The plaintext key is only returned in this response; the server stores a SHA-256 hash.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        key  body      APIKeyCreate  true  "Key name, scopes and optional expiry"
+// @Success      201  {object}  APIKeySecret
+// @Failure      400  {object}  Error  "Bad request"
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks apikeys:admin or a requested scope, or is bound to another tenant)"
+// @Failure      409  {object}  Error  "Name already in use"
+// @Failure      422  {object}  Error  "Unknown scope or tenant, global scope for a tenant-bound key, or invalid expiry"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Router       /admin/api-keys [post]
+func (h *Handler) createAPIKey(ctx context.Context, c *app.RequestContext) {
+	var payload APIKeyCreate
+	if err := bindJSON(c, &payload); err != nil {
+		invalidBody(c, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(payload.Name) == "" {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "name is required"})
+		return
+	}
+	var expiresAt *time.Time
+	if payload.ExpiresAt != nil {
+		t, err := time.Parse(time.RFC3339, *payload.ExpiresAt)
+		if err != nil || !t.After(time.Now()) {
+			writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "expiresAt must be a future RFC 3339 timestamp"})
+			return
+		}
+		expiresAt = &t
+	}
+	tenant, ok := newCredentialTenant(c, payload.Tenant)
+	if !ok || !h.canGrantScopes(ctx, c, payload.Scopes) {
+		return
+	}
+
+	k, err := CreateAPIKey(ctx, h.db, payload.Name, payload.Scopes, expiresAt, tenant)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownScope), errors.Is(err, ErrUnknownTenant), errors.Is(err, ErrGlobalScope):
+			writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: err.Error()})
+		case errors.Is(err, ErrAPIKeyNameTaken):
+			writeError(c, http.StatusConflict, Error{Code: "CONFLICT", Message: err.Error()})
+		default:
+			internalError(ctx, c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, k)
+}
+
+// listAPIKeys godoc
+// @Summary      List API keys
+// @Description  Returns key metadata (never the keys themselves), including revoked and expired keys. Tenant-bound callers only see keys bound to their tenant.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit   query     int     false  "Maximum number of keys to return (default 50, max 500)"
+// @Param        cursor  query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200     {object}  APIKeyPage
+// @Failure      400     {object}  Error  "Bad request"
+// @Failure      401     {object}  Error  "Unauthorized"
+// @Failure      403     {object}  Error  "Forbidden (token lacks apikeys:admin)"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /admin/api-keys [get]
+func (h *Handler) listAPIKeys(ctx context.Context, c *app.RequestContext) {
+	limit := defaultEventLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxEventLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+	offset := 0
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.Atoi(cursorStr)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		offset = v
+	}
+
+	items, err := ListAPIKeys(ctx, h.db, boundTenant(c), limit+1, offset)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	var nextCursor *string
+	if len(items) > limit {
+		items = items[:limit]
+		next := strconv.Itoa(offset + limit)
+		nextCursor = &next
+	}
+	c.JSON(http.StatusOK, APIKeyPage{Items: items, NextCursor: nextCursor})
+}
+
+// rotateAPIKey godoc
+// @Summary      Rotate an API key
+// @Description  Generates a new secret for the key, keeping its name, scopes and expiry. The old secret stops working immediately.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Key ID or name"
+// @Success      200  {object}  APIKeySecret
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks apikeys:admin or a scope the key holds)"
+// @Failure      404  {object}  Error  "Key not found, revoked or bound to another tenant"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Router       /admin/api-keys/{id}/rotate [post]
+func (h *Handler) rotateAPIKey(ctx context.Context, c *app.RequestContext) {
+	k, err := getAPIKey(ctx, h.db, c.Param("id"), boundTenant(c))
+	// The response carries a working secret, so the caller must be able to grant everything the key holds.
+	if err == nil && !h.canGrantScopes(ctx, c, k.Scopes) {
+		return
+	}
+	var rotated *APIKeySecret
+	if err == nil {
+		rotated, err = RotateAPIKey(ctx, h.db, k.ID, boundTenant(c))
+	}
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: err.Error()})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rotated)
+}
+
+// revokeAPIKey godoc
+// @Summary      Revoke an API key
+// @Description  Disables the key immediately. The record is kept so movies it created remain attributable.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Param        id  path  string  true  "Key ID or name"
+// @Success      204  "Key revoked"
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks apikeys:admin)"
+// @Failure      404  {object}  Error  "Key not found, already revoked or bound to another tenant"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Router       /admin/api-keys/{id} [delete]
+func (h *Handler) revokeAPIKey(ctx context.Context, c *app.RequestContext) {
+	if err := RevokeAPIKey(ctx, h.db, c.Param("id"), boundTenant(c)); err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: err.Error()})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
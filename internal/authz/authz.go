@@ -0,0 +1,35 @@
+// Package authz names the roles and rater authentication modes that both the configuration and the API refer to.
+package authz
+
+import "slices"
+
+// Roles that can be assigned to bearer principals and raters.
+const (
+	RoleViewer    = "viewer"
+	RoleRater     = "rater"
+	RoleEditor    = "editor"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+
+	// RoleNone can be configured as the anonymous or default rater role to grant nothing.
+	RoleNone = "none"
+)
+
+// Roles lists the built-in roles from least to most privileged.
+var Roles = []string{RoleViewer, RoleRater, RoleEditor, RoleModerator, RoleAdmin}
+
+// KnownRole reports whether name is a built-in role or RoleNone.
+func KnownRole(name string) bool {
+	return name == RoleNone || slices.Contains(Roles, name)
+}
+
+// Rater authentication modes.
+const (
+	// RaterAuthPlain trusts any non-empty X-Rater-Id value (the historical behaviour).
+	RaterAuthPlain = "plain"
+	// RaterAuthCompat verifies signed tokens and JWTs but still accepts plain IDs during migration, except IDs
+	// that only exist with a token: server-issued "r_" IDs and IDs an admin issued a token for.
+	RaterAuthCompat = "compat"
+	// RaterAuthStrict only accepts signed rater tokens or JWTs.
+	RaterAuthStrict = "strict"
+)
@@ -0,0 +1,339 @@
+package internal
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	backupPrefix = "movies-"
+	backupSuffix = ".db.gz"
+	// backupTimeLayout sorts lexically in creation order.
+	backupTimeLayout = "20060102T150405.000Z"
+
+	// DefaultBackupRetention is how many backups are kept when no retention is configured.
+	DefaultBackupRetention = 7
+)
+
+var (
+	// ErrBackupsNotConfigured is returned when no backup directory is set.
+	ErrBackupsNotConfigured = errors.New("backup directory is not configured")
+	// ErrInvalidBackup is returned when a restore source fails the integrity or schema version check.
+	ErrInvalidBackup = errors.New("invalid backup")
+)
+
+// backupMu serialises backups taken by the server and the CLI within one process.
+var backupMu sync.Mutex
+
+// WithBackups sets the directory backups are written to and how many are kept; keep <= 0 keeps the default.
+func WithBackups(dir string, keep int) HandlerOption {
+	return func(h *Handler) {
+		h.backupDir = dir
+		if keep > 0 {
+			h.backupRetention = keep
+		}
+	}
+}
+
+// DatabasePath returns the file path of a SQLite DSN such as "file:/data/movies.db?_foreign_keys=on".
+func DatabasePath(dsn string) (string, error) {
+	path := strings.TrimPrefix(dsn, "file:")
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	if path == "" || path == ":memory:" || strings.Contains(dsn, "mode=memory") {
+		return "", fmt.Errorf("dsn %q does not name a database file", dsn)
+	}
+	return path, nil
+}
+
+// CreateBackup writes a consistent, gzip-compressed copy of the live database to dir using VACUUM INTO,
+// then deletes the oldest backups so that at most keep remain (keep <= 0 keeps all of them).
+func CreateBackup(ctx context.Context, db *sql.DB, dir string, keep int) (*BackupInfo, error) {
+	if dir == "" {
+		return nil, ErrBackupsNotConfigured
+	}
+	backupMu.Lock()
+	defer backupMu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("create backup directory: %w", err)
+	}
+	now := time.Now().UTC()
+	name := backupPrefix + now.Format(backupTimeLayout) + backupSuffix
+	snapshot := filepath.Join(dir, "."+name+".snapshot")
+	defer os.Remove(snapshot)
+
+	// VACUUM INTO reads inside a single transaction, so the copy is consistent even while writers use the WAL.
+	if _, err := db.ExecContext(ctx, `VACUUM INTO ?`, snapshot); err != nil {
+		return nil, fmt.Errorf("vacuum into snapshot: %w", err)
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return nil, fmt.Errorf("read schema version: %w", err)
+	}
+
+	target := filepath.Join(dir, name)
+	if err := gzipFile(snapshot, target); err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if err := pruneBackups(dir, keep); err != nil {
+		return nil, err
+	}
+	return &BackupInfo{Name: name, SizeBytes: st.Size(), CreatedAt: now.Format(time.RFC3339), SchemaVersion: &version}, nil
+}
+
+// ListBackups returns the backups in dir, newest first.
+func ListBackups(dir string) ([]BackupInfo, error) {
+	if dir == "" {
+		return nil, ErrBackupsNotConfigured
+	}
+	names, err := backupNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]BackupInfo, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		st, err := os.Stat(filepath.Join(dir, names[i]))
+		if err != nil {
+			return nil, err
+		}
+		info := BackupInfo{Name: names[i], SizeBytes: st.Size()}
+		if t, err := time.Parse(backupTimeLayout, strings.TrimSuffix(strings.TrimPrefix(names[i], backupPrefix), backupSuffix)); err == nil {
+			info.CreatedAt = t.Format(time.RFC3339)
+		}
+		res = append(res, info)
+	}
+	return res, nil
+}
+
+// RestoreBackup replaces the database file at dbPath with src, which may be gzip-compressed.
+// The copy must pass PRAGMA integrity_check and must not have a newer schema version than this build supports.
+// The replaced database and its WAL files are kept alongside with a .pre-restore suffix.
+// It holds the exclusive database lock throughout and returns ErrDatabaseInUse while the server or another
+// command has the database open.
+func RestoreBackup(ctx context.Context, src, dbPath string) (string, error) {
+	lock, err := lockDatabase(dbPath, true)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Close()
+
+	staged := dbPath + ".restore"
+	defer os.Remove(staged)
+	if err := stageBackup(src, staged); err != nil {
+		return "", err
+	}
+	if err := verifyBackup(ctx, staged); err != nil {
+		return "", err
+	}
+
+	previous := ""
+	if _, err := os.Stat(dbPath); err == nil {
+		previous = dbPath + ".pre-restore-" + time.Now().UTC().Format(backupTimeLayout)
+		// The WAL and shared-memory files move with the database so that the kept copy stays complete.
+		for _, suffix := range []string{"", "-wal", "-shm"} {
+			if err := os.Rename(dbPath+suffix, previous+suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", fmt.Errorf("move current database aside: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		return "", fmt.Errorf("swap in restored database: %w", err)
+	}
+	return previous, nil
+}
+
+func stageBackup(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	var r io.Reader = in
+	if strings.HasSuffix(src, ".gz") {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("stage backup: %w", err)
+	}
+	return out.Close()
+}
+
+func verifyBackup(ctx context.Context, path string) error {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("%w: integrity check: %s", ErrInvalidBackup, result)
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if version > SchemaVersion() {
+		return fmt.Errorf("%w: schema version %d is newer than supported version %d", ErrInvalidBackup, version, SchemaVersion())
+	}
+	var tables int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'movies'`).Scan(&tables); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("%w: no movies table", ErrInvalidBackup)
+	}
+	return nil
+}
+
+func gzipFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	part := dst + ".part"
+	out, err := os.OpenFile(part, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(part)
+
+	gz := gzip.NewWriter(out)
+	if _, err := io.Copy(gz, in); err != nil {
+		out.Close()
+		return fmt.Errorf("compress backup: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		out.Close()
+		return fmt.Errorf("compress backup: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(part, dst)
+}
+
+// backupNames returns the backup file names in dir, oldest first.
+func backupNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), backupPrefix) && strings.HasSuffix(e.Name(), backupSuffix) {
+			names = append(names, e.Name())
+		}
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func pruneBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+	names, err := backupNames(dir)
+	if err != nil {
+		return err
+	}
+	for len(names) > keep {
+		if err := os.Remove(filepath.Join(dir, names[0])); err != nil {
+			return fmt.Errorf("prune backup: %w", err)
+		}
+		names = names[1:]
+	}
+	return nil
+}
+
+// createBackup godoc
+// @Summary      Take a database backup
+// @Description  Writes a consistent online snapshot of the SQLite database (VACUUM INTO) to the backup directory as gzip and prunes old backups beyond the retention count.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      201  {object}  BackupInfo
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks backups:admin or is bound to a tenant)"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Failure      501  {object}  Error  "Backup directory not configured"
+// @Router       /admin/backups [post]
+func (h *Handler) createBackup(ctx context.Context, c *app.RequestContext) {
+	info, err := CreateBackup(ctx, h.db, h.backupDir, h.backupRetention)
+	if err != nil {
+		if errors.Is(err, ErrBackupsNotConfigured) {
+			writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: err.Error()})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, info)
+}
+
+// listBackups godoc
+// @Summary      List database backups
+// @Description  Returns the backups in the backup directory, newest first.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   BackupInfo
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks backups:admin or is bound to a tenant)"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Failure      501  {object}  Error  "Backup directory not configured"
+// @Router       /admin/backups [get]
+func (h *Handler) listBackups(ctx context.Context, c *app.RequestContext) {
+	backups, err := ListBackups(h.backupDir)
+	if err != nil {
+		if errors.Is(err, ErrBackupsNotConfigured) {
+			writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: err.Error()})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, backups)
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestoreBackupRefusesWhileDatabaseIsOpen(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "movies.db")
+	db, err := initDB(ctx, "file:"+dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := CreateBackup(ctx, db, filepath.Join(dir, "backups"), 0)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "backups", info.Name)
+
+	// The shared lock stands in for a running server.
+	lock, err := lockDatabase(dbPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreBackup(ctx, src, dbPath); !errors.Is(err, ErrDatabaseInUse) {
+		t.Fatalf("restore while locked: error = %v, want %v", err, ErrDatabaseInUse)
+	}
+	lock.Close()
+
+	previous, err := RestoreBackup(ctx, src, dbPath)
+	if err != nil {
+		t.Fatalf("restore after unlock: %v", err)
+	}
+	if previous == "" {
+		t.Fatal("replaced database was not kept")
+	}
+	if _, err := lockDatabase(dbPath, false); err != nil {
+		t.Fatalf("restore left the database locked: %v", err)
+	}
+}
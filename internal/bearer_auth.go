@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"Robin-Camp/internal/https"
+	"Robin-Camp/internal/jwt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Scopes (permissions) that gate routes; see routePolicies for the route matrix and rolePermissions for roles.
+const (
+	ScopeMoviesRead     = "movies:read"
+	ScopeMoviesWrite    = "movies:write"
+	ScopeRatingsRead    = "ratings:read"
+	ScopeRatingsWrite   = "ratings:write"
+	ScopeRatingsAdmin   = "ratings:admin"
+	ScopeBoxOfficeAdmin = "boxoffice:admin"
+	ScopeAPIKeysAdmin   = "apikeys:admin"
+	ScopeRolesAdmin     = "roles:admin"
+	ScopeTenantsAdmin   = "tenants:admin"
+	ScopeBackupsAdmin   = "backups:admin"
+	ScopeMetricsRead    = "metrics:read"
+
+	principalKey = "principal"
+)
+
+// Principal is the caller identity established by bearer authentication.
+type Principal struct {
+	// Subject is the API key name or JWT sub; it is empty for the shared AUTH_TOKEN.
+	Subject string
+	Scopes  []string
+	// Tenant is set when the credential is bound to a tenant: an API key or OAuth client created for it, or a
+	// JWT tenant claim. Unbound credentials other than AUTH_TOKEN may only act on the default tenant.
+	Tenant string
+	// All is set for the static AUTH_TOKEN, which predates scopes and keeps full access.
+	All bool
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	return p.All || slices.Contains(p.Scopes, scope)
+}
+
+// WithBearerJWT accepts JWT bearer tokens checked by v in addition to the static AUTH_TOKEN.
+func WithBearerJWT(v *jwt.Verifier) HandlerOption {
+	return func(h *Handler) {
+		h.bearerVerifier = v
+	}
+}
+
+// WithAdminClientCerts requires /admin routes to arrive over a TLS connection that presented a verified client
+// certificate, on top of their bearer credential.
+func WithAdminClientCerts() HandlerOption {
+	return func(h *Handler) {
+		h.adminClientCerts = true
+	}
+}
+
+// requireClientCert rejects requests whose connection did not present a verified client certificate.
+func requireClientCert(next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if https.ClientCertificate(c) == nil {
+			writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "a verified client certificate is required"})
+			return
+		}
+		next(ctx, c)
+	}
+}
+
+// WithAPIKeyAuth accepts API keys from the api_keys table as bearer credentials. Enabling it turns bearer
+// authentication on even when no static token or JWT verifier is configured, however many keys exist.
+func WithAPIKeyAuth(enabled bool) HandlerOption {
+	return func(h *Handler) {
+		h.apiKeyAuth = enabled
+	}
+}
+
+// bearerAuthEnabled reports whether any bearer credential source is configured; without one, scoped routes stay
+// open for local use. It depends only on configuration, never on the credentials currently stored.
+func (h *Handler) bearerAuthEnabled() bool {
+	return h.authToken != "" || h.bearerVerifier != nil || h.oauthVerifier != nil || h.apiKeyAuth
+}
+
+// authenticateBearer resolves the Authorization header (static token, API key, locally issued or external JWT) to a principal,
+// or returns nil when it is missing or invalid.
+func (h *Handler) authenticateBearer(ctx context.Context, raw string) *Principal {
+	const prefix = "Bearer "
+	if !strings.HasPrefix(raw, prefix) {
+		return nil
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(raw, prefix))
+	if token == "" {
+		return nil
+	}
+
+	if h.authToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.authToken)) == 1 {
+		return &Principal{All: true}
+	}
+	if strings.HasPrefix(token, apiKeyPrefix) {
+		if !h.apiKeyAuth {
+			return nil
+		}
+		return h.authenticateAPIKey(ctx, token)
+	}
+	if !jwt.LooksLikeJWT(token) {
+		return nil
+	}
+	if claims, handled := h.verifyOAuthToken(ctx, token); handled {
+		if claims == nil {
+			return nil
+		}
+		return &Principal{Subject: claims.Subject, Scopes: claims.Scopes(), Tenant: claims.Tenant}
+	}
+	if h.bearerVerifier != nil {
+		claims, err := h.bearerVerifier.Verify(token)
+		if err != nil {
+			return nil
+		}
+		return &Principal{Subject: claims.Subject, Scopes: claims.Scopes(), Tenant: claims.Tenant}
+	}
+	return nil
+}
+
+// requireScope wraps handlers that need a bearer principal holding scope, either granted by the token itself or
+// through a role assigned to its subject in the request's tenant; valid credentials lacking it get 403. With bearer auth disabled, requests
+// without an Authorization header pass, but a credential that cannot be verified is still rejected.
+func (h *Handler) requireScope(scope string, next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		raw := string(c.GetHeader("Authorization"))
+		if raw == "" && !h.bearerAuthEnabled() {
+			next(ctx, c)
+			return
+		}
+
+		p := h.authenticateBearer(ctx, raw)
+		if p == nil {
+			writeError(c, http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
+			return
+		}
+		switch {
+		case p.Tenant != "":
+			// Global scopes administer every tenant, so a role cannot extend them to a tenant-bound credential either.
+			if slices.Contains(globalScopes, scope) {
+				writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "scope " + scope + " is not available to tenant-bound credentials"})
+				return
+			}
+			if tenantExplicit(c) && currentTenant(c) != p.Tenant {
+				writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "token is not valid for tenant " + currentTenant(c)})
+				return
+			}
+			c.Set(tenantKey, p.Tenant)
+		case !p.All && currentTenant(c) != DefaultTenant:
+			writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "credential is not bound to tenant " + currentTenant(c)})
+			return
+		}
+		// Roles are assigned per tenant, so they are looked up once the tenant is settled.
+		if !p.HasScope(scope) && !h.subjectHasPermission(ctx, currentTenant(c), subjectPrincipal, p.Subject, scope) {
+			writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "token lacks scope " + scope})
+			return
+		}
+
+		c.Set(principalKey, p)
+		next(ctx, c)
+	}
+}
+
+// newCredentialTenant returns the tenant a credential created by the caller is bound to. A tenant-bound caller can
+// only create credentials for its own tenant, so it cannot mint a key that escapes that binding; requested may be
+// empty to inherit it. It writes 403 and returns false when the request asks for another tenant.
+func newCredentialTenant(c *app.RequestContext, requested string) (string, bool) {
+	requested = strings.TrimSpace(requested)
+	p := currentPrincipal(c)
+	if p == nil || p.Tenant == "" {
+		return requested, true
+	}
+	if requested != "" && requested != p.Tenant {
+		writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "token is not valid for tenant " + requested})
+		return "", false
+	}
+	return p.Tenant, true
+}
+
+// canGrantScopes reports whether the caller may hand out scopes in a credential it creates or rotates: it must hold
+// each of them itself, through its token or an assigned role. Otherwise it writes 403 and returns false.
+func (h *Handler) canGrantScopes(ctx context.Context, c *app.RequestContext, scopes []string) bool {
+	p := currentPrincipal(c)
+	if p == nil {
+		return true
+	}
+	for _, s := range scopes {
+		if !p.HasScope(s) && !h.subjectHasPermission(ctx, currentTenant(c), subjectPrincipal, p.Subject, s) {
+			writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "cannot grant scope " + s + " that the token lacks"})
+			return false
+		}
+	}
+	return true
+}
+
+// boundTenant returns the tenant the caller's credential is bound to, or "" for unbound credentials and for
+// requests without a principal. Admin routes that manage credentials only reach those of the bound tenant.
+func boundTenant(c *app.RequestContext) string {
+	if p := currentPrincipal(c); p != nil {
+		return p.Tenant
+	}
+	return ""
+}
+
+// currentPrincipal returns the principal established by requireScope, or nil when bearer auth is disabled.
+func currentPrincipal(c *app.RequestContext) *Principal {
+	if v, ok := c.Get(principalKey); ok {
+		p, _ := v.(*Principal)
+		return p
+	}
+	return nil
+}
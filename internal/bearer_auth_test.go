@@ -0,0 +1,26 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestAPIKeysWithoutStaticToken(t *testing.T) {
+	h, engine := newTestServer(t, "", WithAPIKeyAuth(true))
+	k, err := CreateAPIKey(context.Background(), h.db, "ops", []string{ScopeAPIKeysAdmin}, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, do(engine, "GET", "/admin/api-keys", "", bearer(k.Key)...), http.StatusOK)
+	expectStatus(t, do(engine, "GET", "/admin/api-keys", ""), http.StatusUnauthorized)
+
+	if err := RevokeAPIKey(context.Background(), h.db, k.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	// With no active key left, authentication must stay on rather than fail open.
+	expectStatus(t, do(engine, "GET", "/admin/api-keys", ""), http.StatusUnauthorized)
+	expectStatus(t, do(engine, "GET", "/admin/api-keys", "", bearer(k.Key)...), http.StatusUnauthorized)
+	expectStatus(t, do(engine, "POST", "/movies", `{"title":"Heat","genre":"Drama","releaseDate":"2020-01-01"}`), http.StatusUnauthorized)
+}
@@ -0,0 +1,80 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const defaultMaxBodySize = 64 << 10
+
+// largeBodyRoutes accept uploads bounded only by the server-wide limit instead of the per-route one.
+var largeBodyRoutes = map[string]bool{
+	http.MethodPost + " /movies:batch": true,
+}
+
+// WithMaxBodySize sets the largest request body, in bytes, that routes other than bulk imports accept.
+// Non-positive values keep the default of 64 KiB.
+func WithMaxBodySize(n int) HandlerOption {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodySize = n
+		}
+	}
+}
+
+// bodyLimit returns the largest body the route accepts, or 0 when only the server-wide limit applies.
+func (h *Handler) bodyLimit(p routePolicy) int {
+	if largeBodyRoutes[p.Method+" "+p.Path] {
+		return 0
+	}
+	return h.maxBodySize
+}
+
+// limitBody answers 413 before authentication when the request body exceeds limit.
+func limitBody(limit int, next app.HandlerFunc) app.HandlerFunc {
+	if limit <= 0 {
+		return next
+	}
+	return func(ctx context.Context, c *app.RequestContext) {
+		if n := len(c.Request.Body()); n > limit {
+			writeError(c, http.StatusRequestEntityTooLarge, Error{
+				Code:    "PAYLOAD_TOO_LARGE",
+				Message: fmt.Sprintf("request body is %d bytes; this endpoint accepts at most %d", n, limit),
+			})
+			return
+		}
+		next(ctx, c)
+	}
+}
+
+// bindJSON decodes the JSON request body into v. Unlike c.Bind it rejects fields v does not define, since every
+// request schema sets additionalProperties: false, and anything after the first JSON value. An empty body leaves
+// v unchanged so that handlers report the missing fields themselves.
+func bindJSON(c *app.RequestContext, v any) error {
+	body := c.Request.Body()
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return errors.New("unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// invalidBody reports a body bindJSON rejected, naming the offending field or syntax error.
+func invalidBody(c *app.RequestContext, status int, err error) {
+	writeError(c, status, Error{Code: "BAD_REQUEST", Message: "Invalid request body: " + strings.TrimPrefix(err.Error(), "json: ")})
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimits(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken, WithMaxBodySize(128))
+	padded := `{"title":"Heat","genre":"Crime","releaseDate":"1995-12-15","distributor":"` + strings.Repeat("x", 128) + `"}`
+
+	tests := []struct {
+		name    string
+		path    string
+		headers []string
+		want    int
+	}{
+		{"oversized JSON body", "/movies", bearer(testAuthToken), http.StatusRequestEntityTooLarge},
+		// The limit applies before authentication, so an anonymous caller learns nothing else.
+		{"oversized body without credentials", "/movies", nil, http.StatusRequestEntityTooLarge},
+		{"bulk import is exempt", "/movies:batch", bearer(testAuthToken), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(engine, "POST", tt.path, padded, tt.headers...)
+			expectStatus(t, w, tt.want)
+			if tt.want == http.StatusRequestEntityTooLarge {
+				var e Error
+				decode(t, w, &e)
+				if e.Code != "PAYLOAD_TOO_LARGE" {
+					t.Fatalf("code = %s, want PAYLOAD_TOO_LARGE", e.Code)
+				}
+			}
+		})
+	}
+}
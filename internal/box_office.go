@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Robin-Camp/internal/boxoffice"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// toBoxOffice converts the upstream payload to the API representation.
+func toBoxOffice(bo *boxoffice.BoxOffice) *BoxOffice {
+	var worldwide int64
+	if bo.Revenue.Worldwide != nil {
+		worldwide = *bo.Revenue.Worldwide
+	}
+	return &BoxOffice{
+		Revenue: Revenue{
+			Worldwide:         worldwide,
+			OpeningWeekendUsa: bo.Revenue.OpeningWeekendUSA,
+		},
+		Currency:    bo.Currency,
+		Source:      bo.Source,
+		LastUpdated: bo.LastUpdated,
+	}
+}
+
+// storeBoxOffice replaces the movie's box office data and bumps its updated_at.
+func storeBoxOffice(ctx context.Context, tx *sql.Tx, movieID, tenantID string, box *BoxOffice) error {
+	_, err := tx.ExecContext(ctx, `INSERT OR REPLACE INTO box_office (movie_id, tenant_id, currency, source, last_updated, revenue_worldwide, revenue_opening_weekend_usa) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		movieID, tenantID, box.Currency, box.Source, box.LastUpdated, box.Revenue.Worldwide, valueOrZero(box.Revenue.OpeningWeekendUsa),
+	)
+	if err != nil {
+		return fmt.Errorf("upsert box_office: %w", err)
+	}
+	// The box office data is part of the movie representation, so its validators must change too.
+	if _, err := tx.ExecContext(ctx, `UPDATE movies SET updated_at = (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')) WHERE id = ?`, movieID); err != nil {
+		return fmt.Errorf("touch movie: %w", err)
+	}
+	return nil
+}
+
+// refreshBoxOffice godoc
+// @Summary      Refresh box office data for a movie
+// @Description  Re-fetches box office figures from the upstream API and replaces the stored values.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        title  path      string  true  "Movie title"
+// @Success      200    {object}  BoxOffice
+// @Failure      401    {object}  Error  "Unauthorized"
+// @Failure      403    {object}  Error  "Forbidden (token lacks boxoffice:admin)"
+// @Failure      404    {object}  Error  "Movie or box office data not found"
+// @Failure      502    {object}  Error  "Upstream box office API failed"
+// @Failure      500    {object}  Error  "Internal server error"
+// @Router       /movies/{title}/boxoffice/refresh [post]
+func (h *Handler) refreshBoxOffice(ctx context.Context, c *app.RequestContext) {
+	title := strings.TrimSpace(c.Param("title"))
+
+	var movieID string
+	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, currentTenant(c), title).Scan(&movieID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	client, err := h.boxClientFor(ctx, currentTenant(c))
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	if client == nil {
+		writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: "box office client is not configured"})
+		return
+	}
+	bo, err := client.GetMovieBoxOffice(ctx, title)
+	if err != nil {
+		if errors.Is(err, boxoffice.ErrNotFound) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "no box office data for movie"})
+			return
+		}
+		hlog.CtxWarnf(ctx, "box office refresh %q: %v", title, err)
+		writeError(c, http.StatusBadGateway, Error{Code: "UPSTREAM", Message: "box office upstream request failed"})
+		return
+	}
+
+	box := toBoxOffice(bo)
+	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		return storeBoxOffice(ctx, tx, movieID, currentTenant(c), box)
+	}); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, box)
+}
@@ -8,15 +8,22 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	endpointPath       = "/boxoffice"
 	defaultHTTPTimeout = 5 * time.Second
 	maxErrorBodyBytes  = 64 * 1024
+	tracerName         = "Robin-Camp/internal/boxoffice"
 )
 
 var (
@@ -35,6 +42,7 @@ type Client struct {
 	baseURL    *url.URL
 	apiKey     string
 	httpClient *http.Client
+	breaker    *breaker
 }
 
 // Option allows customizing the client.
@@ -72,6 +80,7 @@ func NewClient(baseURL, apiKey string, opts ...Option) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: defaultHTTPTimeout,
 		},
+		breaker: &breaker{threshold: defaultBreakerThreshold, cooldown: defaultBreakerCooldown, now: time.Now},
 	}
 
 	for _, opt := range opts {
@@ -81,11 +90,6 @@ func NewClient(baseURL, apiKey string, opts ...Option) (*Client, error) {
 	return client, nil
 }
 
-// NewFromEnv constructs a Client using BOXOFFICE_URL and BOXOFFICE_API_KEY environment variables.
-func NewFromEnv(opts ...Option) (*Client, error) {
-	return NewClient(os.Getenv("BOXOFFICE_URL"), os.Getenv("BOXOFFICE_API_KEY"), opts...)
-}
-
 // UpstreamError captures non-200 responses from the Box Office API.
 type UpstreamError struct {
 	StatusCode int
@@ -122,8 +126,33 @@ func (c *Client) GetMovieBoxOffice(ctx context.Context, title string) (*BoxOffic
 		return nil, ErrEmptyTitle
 	}
 
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "boxoffice.GetMovieBoxOffice",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("movie.title", trimmedTitle), semconv.ServerAddress(c.baseURL.Hostname())),
+	)
+	defer span.End()
+
+	if c.breaker != nil {
+		if err := c.breaker.allow(); err != nil {
+			span.SetStatus(codes.Error, err.Error())
+			return nil, err
+		}
+	}
+	record, err := c.fetch(ctx, trimmedTitle)
+	if c.breaker != nil {
+		c.breaker.record(err)
+	}
+	// A title the upstream does not know is an expected answer, not a failed call.
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return record, err
+}
+
+func (c *Client) fetch(ctx context.Context, title string) (*BoxOffice, error) {
 	values := url.Values{}
-	values.Set("title", trimmedTitle)
+	values.Set("title", title)
 
 	reqURL := c.baseURL.ResolveReference(&url.URL{Path: endpointPath, RawQuery: values.Encode()})
 
@@ -134,12 +163,15 @@ func (c *Client) GetMovieBoxOffice(ctx context.Context, title string) (*BoxOffic
 
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("X-API-Key", c.apiKey)
+	// Propagate the W3C trace context so upstream spans join the caller's trace.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("boxoffice: execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	trace.SpanFromContext(ctx).SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
 
 	if resp.StatusCode != http.StatusOK {
 		upstreamErr := &UpstreamError{StatusCode: resp.StatusCode}
@@ -158,6 +190,21 @@ func (c *Client) GetMovieBoxOffice(ctx context.Context, title string) (*BoxOffic
 	return &record, nil
 }
 
+// Ping checks that the upstream answers HTTP at all; any status code counts as reachable.
+// It does not use or affect the circuit breaker.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.baseURL.String(), nil)
+	if err != nil {
+		return fmt.Errorf("boxoffice: build request: %w", err)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("boxoffice: ping: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
 func decodeError(r io.Reader) *Error {
 	limited := io.LimitReader(r, maxErrorBodyBytes)
 	var payload Error
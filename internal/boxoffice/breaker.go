@@ -0,0 +1,100 @@
+package boxoffice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Circuit states reported by Client.CircuitState.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting the upstream after repeated failures, until the cooldown passes.
+var ErrCircuitOpen = errors.New("boxoffice: circuit open, upstream temporarily skipped")
+
+// WithCircuitBreaker opens the circuit after threshold consecutive failed calls and lets one trial call through
+// once cooldown has passed. A non-positive threshold disables the breaker; a non-positive cooldown keeps the default.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *Client) {
+		if threshold <= 0 {
+			c.breaker = nil
+			return
+		}
+		if cooldown <= 0 {
+			cooldown = defaultBreakerCooldown
+		}
+		c.breaker = &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+	}
+}
+
+// CircuitState reports whether calls currently reach the upstream.
+func (c *Client) CircuitState() string {
+	if c == nil || c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.state()
+}
+
+// breaker counts consecutive failures. A 404 is an answer, not a failure, and neither is a call the caller cancelled.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool // a half-open trial call is in flight
+}
+
+func (b *breaker) state() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case b.failures < b.threshold:
+		return CircuitClosed
+	case b.trial || b.now().Sub(b.openedAt) >= b.cooldown:
+		return CircuitHalfOpen
+	default:
+		return CircuitOpen
+	}
+}
+
+func (b *breaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	if b.trial || b.now().Sub(b.openedAt) < b.cooldown {
+		return ErrCircuitOpen
+	}
+	b.trial = true
+	return nil
+}
+
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	switch {
+	case errors.Is(err, context.Canceled):
+	case err == nil || errors.Is(err, ErrNotFound):
+		b.failures = 0
+	default:
+		b.failures++
+		if b.failures >= b.threshold {
+			b.openedAt = b.now()
+		}
+	}
+}
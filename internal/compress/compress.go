@@ -0,0 +1,217 @@
+// Package compress encodes responses with gzip or zstd when the client's Accept-Encoding allows it.
+// Buffered responses are compressed by Middleware once the handler has finished; streamed responses,
+// such as exports, wrap their writer with NewStreamWriter before the first write.
+package compress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Content codings this package produces.
+const (
+	Gzip = "gzip"
+	Zstd = "zstd"
+)
+
+// preference breaks ties between codings the client weights equally; zstd is faster and smaller.
+var preference = []string{Zstd, Gzip}
+
+// Negotiate returns the coding to use for a request with the given Accept-Encoding header, or "" to send the
+// body as is. Quality values are honoured, q=0 refuses a coding and "*" stands for any coding not listed.
+func Negotiate(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	weights := make(map[string]float64)
+	wildcard := -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(key, "q") {
+				if v, err := strconv.ParseFloat(value, 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+		} else {
+			weights[name] = q
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, coding := range preference {
+		q, ok := weights[coding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+// compressible reports whether responses of this media type are worth compressing.
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/json", mediaType == "application/x-ndjson", strings.HasSuffix(mediaType, "+json"):
+		return true
+	}
+	return false
+}
+
+// Middleware compresses buffered responses of at least minSize bytes whose media type is textual. Responses
+// that are empty by definition (HEAD, 204, 304), already encoded, or streamed are passed through. Compressed
+// responses carry a strong ETag of their own, the identity tag suffixed with the coding (see EncodedETag),
+// since their bytes differ from the representation the tag was computed over.
+func Middleware(minSize int) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		c.Set(enabledKey, true)
+		c.Next(ctx)
+
+		resp := &c.Response
+		status := resp.StatusCode()
+		if string(c.Method()) == http.MethodHead || status < http.StatusOK ||
+			status == http.StatusNoContent || status == http.StatusNotModified {
+			return
+		}
+		if len(resp.Header.ContentEncoding()) > 0 || resp.IsBodyStream() || resp.GetHijackWriter() != nil {
+			return
+		}
+		if !compressible(string(resp.Header.ContentType())) {
+			return
+		}
+		resp.Header.Add("Vary", "Accept-Encoding")
+		body := resp.Body()
+		if len(body) < minSize {
+			return
+		}
+		coding := Negotiate(string(c.GetHeader("Accept-Encoding")))
+		if coding == "" {
+			return
+		}
+		encoded := encode(coding, body)
+		if len(encoded) >= len(body) {
+			return
+		}
+		resp.SetBodyRaw(encoded)
+		resp.Header.Set("Content-Encoding", coding)
+		tagEncoding(c, coding)
+	}
+}
+
+// EncodedETag returns the strong entity tag of the coding-encoded representation whose identity tag is etag,
+// e.g. "abc" becomes "abc-gzip". Weak and malformed tags are returned unchanged.
+func EncodedETag(etag, coding string) string {
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+	return etag[:len(etag)-1] + "-" + coding + `"`
+}
+
+// IdentityETag undoes EncodedETag, so that validators a client received with a compressed response can be
+// compared against the identity tag the server computes.
+func IdentityETag(etag string) string {
+	for _, coding := range preference {
+		if suffix := "-" + coding + `"`; strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, suffix) {
+			return etag[:len(etag)-len(suffix)] + `"`
+		}
+	}
+	return etag
+}
+
+func tagEncoding(c *app.RequestContext, coding string) {
+	if etag := c.Response.Header.Get("ETag"); etag != "" {
+		c.Response.Header.Set("ETag", EncodedETag(etag, coding))
+	}
+}
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+	// zstdEncoder only serves EncodeAll, which is safe for concurrent use.
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+)
+
+func encode(coding string, body []byte) []byte {
+	if coding == Zstd {
+		return zstdEncoder.EncodeAll(body, make([]byte, 0, len(body)/2))
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(body) / 2)
+	zw := gzipWriters.Get().(*gzip.Writer)
+	defer gzipWriters.Put(zw)
+	zw.Reset(&buf)
+	zw.Write(body)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// enabledKey marks requests that passed through Middleware, so that compression is switched on and off in one place.
+const enabledKey = "compress.enabled"
+
+// NewStreamWriter negotiates a coding for a streamed response and returns a writer that compresses into w.
+// It sets Content-Encoding and Vary on c, so it must be called before the first byte is sent. Every Write is
+// flushed through the encoder, letting the client decode each chunk as it arrives; Close ends the stream.
+// When Middleware is not installed or the client accepts no supported coding, writes go to w unchanged.
+func NewStreamWriter(c *app.RequestContext, w io.Writer) io.WriteCloser {
+	if !c.GetBool(enabledKey) {
+		return nopCloser{w}
+	}
+	c.Response.Header.Add("Vary", "Accept-Encoding")
+	coding := Negotiate(string(c.GetHeader("Accept-Encoding")))
+	if coding == "" {
+		return nopCloser{w}
+	}
+	c.Response.Header.Set("Content-Encoding", coding)
+	tagEncoding(c, coding)
+	if coding == Zstd {
+		zw, _ := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+		return &streamWriter{enc: zw}
+	}
+	return &streamWriter{enc: gzip.NewWriter(w)}
+}
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+}
+
+type streamWriter struct {
+	enc encoder
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	n, err := s.enc.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, s.enc.Flush()
+}
+
+func (s *streamWriter) Close() error {
+	return s.enc.Close()
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,144 @@
+package compress
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", Gzip},
+		{"gzip, zstd", Zstd},
+		{"gzip;q=1, zstd;q=0.5", Gzip},
+		{"zstd;q=0, gzip", Gzip},
+		{"*", Zstd},
+		{"*;q=0.1, gzip;q=0.5", Gzip},
+		{"*, zstd;q=0", Gzip},
+		{"*;q=0", ""},
+		{"GZIP", Gzip},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestETags(t *testing.T) {
+	for _, coding := range []string{Gzip, Zstd} {
+		encoded := EncodedETag(`"abc"`, coding)
+		if encoded != `"abc-`+coding+`"` || IdentityETag(encoded) != `"abc"` {
+			t.Errorf("%s: encoded %s, identity %s", coding, encoded, IdentityETag(encoded))
+		}
+	}
+	// Weak and malformed tags pass through, as do identity tags.
+	for _, tag := range []string{`W/"abc"`, `abc`} {
+		if got := EncodedETag(tag, Gzip); got != tag {
+			t.Errorf("EncodedETag(%s) = %s, want it unchanged", tag, got)
+		}
+	}
+	for _, tag := range []string{`W/"abc"`, `abc`, `"abc"`, `"abc-br"`} {
+		if got := IdentityETag(tag); got != tag {
+			t.Errorf("IdentityETag(%s) = %s, want it unchanged", tag, got)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	const minSize = 64
+	large := `{"items":"` + strings.Repeat("movie ", 100) + `"}`
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(Middleware(minSize))
+	serve := func(contentType, body string) app.HandlerFunc {
+		return func(ctx context.Context, c *app.RequestContext) {
+			c.Header("ETag", `"v1"`)
+			c.Data(http.StatusOK, contentType, []byte(body))
+		}
+	}
+	engine.GET("/large", serve("application/json", large))
+	engine.HEAD("/large", serve("application/json", large))
+	engine.GET("/small", serve("application/json", `{"ok":true}`))
+	engine.GET("/image", serve("image/png", large))
+	engine.GET("/stream", func(ctx context.Context, c *app.RequestContext) {
+		w := NewStreamWriter(c, c)
+		io.WriteString(w, large)
+		w.Close()
+	})
+
+	tests := []struct {
+		name, method, path, accept string
+		wantCoding                 string
+	}{
+		{"gzip", "GET", "/large", "gzip", Gzip},
+		{"zstd", "GET", "/large", "gzip, zstd", Zstd},
+		{"no acceptable coding", "GET", "/large", "br", ""},
+		{"below the minimum size", "GET", "/small", "gzip", ""},
+		{"binary media type", "GET", "/image", "gzip", ""},
+		{"HEAD", "HEAD", "/large", "gzip", ""},
+		{"streamed", "GET", "/stream", "gzip", Gzip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := ut.PerformRequest(engine, tt.method, tt.path, nil, ut.Header{Key: "Accept-Encoding", Value: tt.accept})
+			resp := w.Result()
+			if got := string(resp.Header.ContentEncoding()); got != tt.wantCoding {
+				t.Fatalf("Content-Encoding = %q, want %q", got, tt.wantCoding)
+			}
+			if tt.wantCoding == "" {
+				return
+			}
+			if vary := resp.Header.Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("Vary = %q", vary)
+			}
+			if tt.path != "/stream" {
+				if etag := resp.Header.Get("ETag"); etag != EncodedETag(`"v1"`, tt.wantCoding) {
+					t.Errorf("ETag = %s, want the coding-specific tag", etag)
+				}
+			}
+			if got := decodeBody(t, tt.wantCoding, resp.Body()); got != large {
+				t.Fatalf("decoded body = %.40q..., want the original", got)
+			}
+		})
+	}
+}
+
+func decodeBody(t *testing.T, coding string, body []byte) string {
+	t.Helper()
+	var r io.Reader
+	switch coding {
+	case Gzip:
+		zr, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r = zr
+	case Zstd:
+		zr, err := zstd.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"Robin-Camp/internal/compress"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// errPreconditionFailed signals that an If-Match header did not match the current representation.
+var errPreconditionFailed = errors.New("precondition failed")
+
+// entityTag returns a strong ETag over the last modification time and the representation bytes.
+func entityTag(lastModified string, body []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(lastModified))
+	sum.Write([]byte{0})
+	sum.Write(body)
+	return `"` + hex.EncodeToString(sum.Sum(nil)[:16]) + `"`
+}
+
+// movieETag is the ETag GET /movies/{title} serves for m.
+func movieETag(m *Movie) (string, error) {
+	body, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return entityTag(m.UpdatedAt, body), nil
+}
+
+// ratingETag identifies a single rater's rating. Submissions within the same millisecond share updated_at,
+// so content (the score and review) is hashed as well.
+func ratingETag(movieID, raterID, updatedAt, content string) string {
+	return entityTag(updatedAt, []byte(movieID+"\x00"+raterID+"\x00"+content))
+}
+
+// writeConditionalJSON answers 200 with v, or 304 when If-None-Match (or, without it, If-Modified-Since)
+// shows that the client's copy is current. lastModified uses the SQLite timestamp layout and may be empty.
+func writeConditionalJSON(ctx context.Context, c *app.RequestContext, v any, lastModified string) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	etag := entityTag(lastModified, body)
+	c.Header("ETag", etag)
+
+	modified, _ := time.Parse(sqliteTimeLayout, lastModified)
+	if !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if inm := string(c.GetHeader("If-None-Match")); inm != "" {
+		if etagListMatches(inm, etag, true) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	} else if ims := string(c.GetHeader("If-Modified-Since")); ims != "" && !modified.IsZero() {
+		// Last-Modified has one-second resolution, so compare at that granularity.
+		if since, err := http.ParseTime(ims); err == nil && !modified.Truncate(time.Second).After(since) {
+			c.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// checkIfMatch evaluates If-Match against the current ETag, which is empty when the resource does not exist.
+// It returns errPreconditionFailed on mismatch and nil when the header is absent.
+func checkIfMatch(c *app.RequestContext, current string) error {
+	im := string(c.GetHeader("If-Match"))
+	if im == "" {
+		return nil
+	}
+	if current == "" || !etagListMatches(im, current, false) {
+		return errPreconditionFailed
+	}
+	return nil
+}
+
+// etagListMatches reports whether the comma-separated header list contains "*" or etag.
+// Weak validators only match when weak comparison is allowed (If-None-Match); If-Match requires strong comparison.
+// Tags of compressed representations name the same state as the identity tag etag, so they match it as well.
+func etagListMatches(header, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if compress.IdentityETag(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func preconditionFailed(c *app.RequestContext) {
+	writeError(c, http.StatusPreconditionFailed, Error{Code: "PRECONDITION_FAILED", Message: "If-Match does not match the current version"})
+}
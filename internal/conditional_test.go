@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestConditionalReads(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	createTestMovie(t, engine, "Heat", "")
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "u1"), http.StatusCreated)
+
+	for _, path := range []string{"/movies", "/movies/Heat", "/movies/Heat/rating"} {
+		t.Run(path, func(t *testing.T) {
+			w := do(engine, "GET", path, "")
+			expectStatus(t, w, http.StatusOK)
+			etag, lastModified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+			if etag == "" || lastModified == "" {
+				t.Fatalf("ETag = %q, Last-Modified = %q; want both", etag, lastModified)
+			}
+
+			tests := []struct {
+				name    string
+				headers []string
+				want    int
+			}{
+				{"matching ETag", []string{"If-None-Match", etag}, http.StatusNotModified},
+				{"weak form of the ETag", []string{"If-None-Match", "W/" + etag}, http.StatusNotModified},
+				{"stale ETag", []string{"If-None-Match", `"stale"`}, http.StatusOK},
+				{"unchanged since Last-Modified", []string{"If-Modified-Since", lastModified}, http.StatusNotModified},
+				{"modified since", []string{"If-Modified-Since", "Mon, 01 Jan 2001 00:00:00 GMT"}, http.StatusOK},
+				{"If-None-Match takes precedence", []string{"If-None-Match", `"stale"`, "If-Modified-Since", lastModified}, http.StatusOK},
+			}
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					w := do(engine, "GET", path, "", tt.headers...)
+					expectStatus(t, w, tt.want)
+					if tt.want == http.StatusNotModified && len(w.Body.Bytes()) != 0 {
+						t.Fatalf("304 has a body: %s", w.Body.String())
+					}
+				})
+			}
+		})
+	}
+
+	t.Run("a new rating changes the aggregate ETag", func(t *testing.T) {
+		etag := do(engine, "GET", "/movies/Heat/rating", "").Header().Get("ETag")
+		expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":2}`, "X-Rater-Id", "u2"), http.StatusCreated)
+		expectStatus(t, do(engine, "GET", "/movies/Heat/rating", "", "If-None-Match", etag), http.StatusOK)
+	})
+}
+
+func TestIfMatch(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	createTestMovie(t, engine, "Heat", "")
+
+	t.Run("movie update", func(t *testing.T) {
+		etag := do(engine, "GET", "/movies/Heat", "").Header().Get("ETag")
+		update := func(genre, ifMatch string) int {
+			body := `{"title":"Heat","genre":"` + genre + `","releaseDate":"2020-01-01"}`
+			return do(engine, "POST", "/movies?onConflict=update", body, append(bearer(testAuthToken), "If-Match", ifMatch)...).Code
+		}
+		if got := update("Crime", `"stale"`); got != http.StatusPreconditionFailed {
+			t.Fatalf("stale If-Match: status = %d, want 412", got)
+		}
+		if got := update("Crime", etag); got != http.StatusOK {
+			t.Fatalf("current If-Match: status = %d, want 200", got)
+		}
+		// The update changed the movie, so the ETag used above no longer matches.
+		if got := update("Thriller", etag); got != http.StatusPreconditionFailed {
+			t.Fatalf("replayed If-Match: status = %d, want 412", got)
+		}
+		if got := update("Thriller", "*"); got != http.StatusOK {
+			t.Fatalf("If-Match *: status = %d, want 200", got)
+		}
+	})
+
+	t.Run("rating update and delete", func(t *testing.T) {
+		w := do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "u1")
+		expectStatus(t, w, http.StatusCreated)
+		first := w.Header().Get("ETag")
+
+		w = do(engine, "POST", "/movies/Heat/ratings", `{"rating":3}`, "X-Rater-Id", "u1", "If-Match", first)
+		expectStatus(t, w, http.StatusOK)
+		second := w.Header().Get("ETag")
+		if second == "" || second == first {
+			t.Fatalf("ETag after update = %q, want a new one (was %q)", second, first)
+		}
+
+		expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":2}`, "X-Rater-Id", "u1", "If-Match", first), http.StatusPreconditionFailed)
+		expectStatus(t, do(engine, "DELETE", "/movies/Heat/ratings", "", "X-Rater-Id", "u1", "If-Match", first), http.StatusPreconditionFailed)
+		expectStatus(t, do(engine, "DELETE", "/movies/Heat/ratings", "", "X-Rater-Id", "u1", "If-Match", second), http.StatusNoContent)
+	})
+}
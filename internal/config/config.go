@@ -0,0 +1,259 @@
+// Package config defines the service configuration and loads it from defaults, an optional YAML file,
+// the environment and command-line flags, each layer overriding the one before.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"Robin-Camp/internal/authz"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the complete service configuration. Each field's yaml tag is its key in the config file
+// and its env tag the environment variable that overrides it; fields tagged secret are redacted by YAML.
+type Config struct {
+	Server      Server      `yaml:"server"`
+	TLS         TLS         `yaml:"tls"`
+	Compression Compression `yaml:"compression"`
+	Database    Database    `yaml:"database"`
+	Auth        Auth        `yaml:"auth"`
+	OAuth       OAuth       `yaml:"oauth"`
+	Raters      Raters      `yaml:"raters"`
+	Reviews     Reviews     `yaml:"reviews"`
+	RateLimit   RateLimit   `yaml:"rateLimit"`
+	Abuse       Abuse       `yaml:"abuse"`
+	BoxOffice   BoxOffice   `yaml:"boxOffice"`
+	Enrichment  Enrichment  `yaml:"enrichment"`
+	Backup      Backup      `yaml:"backup"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	Health      Health      `yaml:"health"`
+	Logging     Logging     `yaml:"logging"`
+	Tracing     Tracing     `yaml:"tracing"`
+}
+
+type Server struct {
+	Address string `yaml:"address" env:"ADDRESS"`
+	Port    int    `yaml:"port" env:"PORT"`
+	Swagger bool   `yaml:"swagger" env:"SWAGGER"`
+	// Zero read, write or idle timeouts mean no limit.
+	ReadTimeout  time.Duration `yaml:"readTimeout" env:"READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"WRITE_TIMEOUT"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout" env:"IDLE_TIMEOUT"`
+	// MaxBodySize caps every request, including bulk imports; MaxJSONBodySize caps the bodies of all other routes.
+	MaxBodySize     int `yaml:"maxBodySize" env:"MAX_BODY_SIZE"`
+	MaxJSONBodySize int `yaml:"maxJSONBodySize" env:"MAX_JSON_BODY_SIZE"`
+	// ShutdownTimeout bounds the whole shutdown; DrainDelay is how long /readyz fails before the listener closes.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	DrainDelay      time.Duration `yaml:"drainDelay" env:"SHUTDOWN_DRAIN_DELAY"`
+	// TrustedProxies is a comma-separated list of CIDRs or IPs of reverse proxies whose X-Forwarded-For and
+	// X-Real-IP headers are believed. Empty means the client IP is always the connection's remote address.
+	TrustedProxies string `yaml:"trustedProxies" env:"TRUSTED_PROXIES"`
+}
+
+// TrustedProxyCIDRs parses TrustedProxies; a bare IP stands for a single-address network.
+func (s Server) TrustedProxyCIDRs() ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, item := range strings.Split(s.TrustedProxies, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP or CIDR %q", item)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP or CIDR %q", item)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// TLS serves HTTPS when CertFile and KeyFile are set. ClientCAFile additionally requires admin routes to
+// present a client certificate signed by one of its CAs; RedirectAddress, when set, listens for plain HTTP
+// there and redirects it to HTTPS.
+type TLS struct {
+	CertFile        string        `yaml:"certFile" env:"TLS_CERT_FILE"`
+	KeyFile         string        `yaml:"keyFile" env:"TLS_KEY_FILE"`
+	ClientCAFile    string        `yaml:"clientCAFile" env:"TLS_CLIENT_CA_FILE"`
+	ReloadInterval  time.Duration `yaml:"reloadInterval" env:"TLS_RELOAD_INTERVAL"`
+	HTTP2           bool          `yaml:"http2" env:"TLS_HTTP2"`
+	RedirectAddress string        `yaml:"redirectAddress" env:"TLS_REDIRECT_ADDRESS"`
+}
+
+// Enabled reports whether the server listens with TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Compression encodes textual responses of at least MinSize bytes with gzip or zstd, as the client's
+// Accept-Encoding allows. Exports are streamed and compressed regardless of size.
+type Compression struct {
+	Enabled bool `yaml:"enabled" env:"COMPRESSION_ENABLED"`
+	MinSize int  `yaml:"minSize" env:"COMPRESSION_MIN_SIZE"`
+}
+
+type Database struct {
+	URL string `yaml:"url" env:"DB_URL"`
+}
+
+type Auth struct {
+	Token string `yaml:"token" env:"AUTH_TOKEN" secret:"true"`
+	// APIKeys accepts keys from the api_keys table and, like Token, turns bearer authentication on.
+	APIKeys          bool   `yaml:"apiKeys" env:"AUTH_API_KEYS"`
+	JWTSecret        string `yaml:"jwtSecret" env:"JWT_HS256_SECRET" secret:"true"`
+	JWKSFile         string `yaml:"jwksFile" env:"JWT_JWKS_FILE"`
+	JWTIssuer        string `yaml:"jwtIssuer" env:"JWT_ISSUER"`
+	JWTAudience      string `yaml:"jwtAudience" env:"JWT_AUDIENCE"`
+	AnonymousRole    string `yaml:"anonymousRole" env:"RBAC_ANONYMOUS_ROLE"`
+	DefaultRaterRole string `yaml:"defaultRaterRole" env:"RBAC_DEFAULT_RATER_ROLE"`
+}
+
+// OAuth configures the built-in client-credentials server, which is disabled while SigningSecret is empty.
+type OAuth struct {
+	SigningSecret string        `yaml:"signingSecret" env:"OAUTH_SIGNING_SECRET" secret:"true"`
+	Issuer        string        `yaml:"issuer" env:"OAUTH_ISSUER"`
+	TokenTTL      time.Duration `yaml:"tokenTTL" env:"OAUTH_TOKEN_TTL"`
+}
+
+type Raters struct {
+	TokenSecret string `yaml:"tokenSecret" env:"RATER_TOKEN_SECRET" secret:"true"`
+	AuthMode    string `yaml:"authMode" env:"RATER_AUTH_MODE"`
+}
+
+type Reviews struct {
+	MaxLength       int    `yaml:"maxLength" env:"REVIEW_MAX_LENGTH"`
+	Wordlist        string `yaml:"wordlist" env:"MODERATION_WORDLIST"`
+	ReportThreshold int    `yaml:"reportThreshold" env:"MODERATION_REPORT_THRESHOLD"`
+}
+
+// RateLimit sets the rating token buckets; a zero rate or burst disables that bucket.
+type RateLimit struct {
+	RaterPerMinute float64 `yaml:"raterPerMinute" env:"RATE_LIMIT_RATER_PER_MINUTE"`
+	RaterBurst     int     `yaml:"raterBurst" env:"RATE_LIMIT_RATER_BURST"`
+	IPPerMinute    float64 `yaml:"ipPerMinute" env:"RATE_LIMIT_IP_PER_MINUTE"`
+	IPBurst        int     `yaml:"ipBurst" env:"RATE_LIMIT_IP_BURST"`
+	// Registration limits POST /raters per client IP.
+	RegistrationPerMinute float64 `yaml:"registrationPerMinute" env:"RATE_LIMIT_REGISTRATION_PER_MINUTE"`
+	RegistrationBurst     int     `yaml:"registrationBurst" env:"RATE_LIMIT_REGISTRATION_BURST"`
+}
+
+type Abuse struct {
+	Window            time.Duration `yaml:"window" env:"ABUSE_WINDOW"`
+	NewRaterThreshold int           `yaml:"newRaterThreshold" env:"ABUSE_NEW_RATER_THRESHOLD"`
+	ScanInterval      time.Duration `yaml:"scanInterval" env:"ABUSE_SCAN_INTERVAL"`
+}
+
+// BoxOffice configures the upstream lookup; leaving both URL and APIKey empty disables it.
+type BoxOffice struct {
+	URL              string        `yaml:"url" env:"BOXOFFICE_URL"`
+	APIKey           string        `yaml:"apiKey" env:"BOXOFFICE_API_KEY" secret:"true"`
+	BreakerThreshold int           `yaml:"breakerThreshold" env:"BOXOFFICE_BREAKER_THRESHOLD"`
+	BreakerCooldown  time.Duration `yaml:"breakerCooldown" env:"BOXOFFICE_BREAKER_COOLDOWN"`
+}
+
+type Enrichment struct {
+	Interval time.Duration `yaml:"interval" env:"ENRICHMENT_INTERVAL"`
+}
+
+// Backup.Dir defaults to a backups directory next to the database file when empty.
+type Backup struct {
+	Dir       string `yaml:"dir" env:"BACKUP_DIR"`
+	Retention int    `yaml:"retention" env:"BACKUP_RETENTION"`
+}
+
+type Idempotency struct {
+	KeyTTL time.Duration `yaml:"keyTTL" env:"IDEMPOTENCY_KEY_TTL"`
+	// Lease is how long an unfinished request holds its key before a retry may take it over.
+	Lease time.Duration `yaml:"lease" env:"IDEMPOTENCY_LEASE"`
+}
+
+type Health struct {
+	CacheTTL time.Duration `yaml:"cacheTTL" env:"HEALTH_CACHE_TTL"`
+}
+
+type Logging struct {
+	Level      string  `yaml:"level" env:"LOG_LEVEL"`
+	SampleRate float64 `yaml:"sampleRate" env:"LOG_SAMPLE_RATE"`
+}
+
+type Tracing struct {
+	Exporter    string `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	ServiceName string `yaml:"serviceName" env:"OTEL_SERVICE_NAME"`
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() Config {
+	return Config{
+		Server: Server{
+			Address:         "0.0.0.0",
+			Port:            8080,
+			ReadTimeout:     3 * time.Minute,
+			IdleTimeout:     3 * time.Minute,
+			MaxBodySize:     4 << 20,
+			MaxJSONBodySize: 64 << 10,
+			ShutdownTimeout: 15 * time.Second,
+		},
+		TLS:         TLS{ReloadInterval: 30 * time.Second, HTTP2: true},
+		Compression: Compression{Enabled: true, MinSize: 1024},
+		Database:    Database{URL: "file:movies.db?_foreign_keys=on"},
+		Auth:        Auth{APIKeys: true, AnonymousRole: authz.RoleViewer, DefaultRaterRole: authz.RoleRater},
+		OAuth:       OAuth{Issuer: "robin-camp", TokenTTL: 15 * time.Minute},
+		Raters:      Raters{AuthMode: authz.RaterAuthCompat},
+		Reviews:     Reviews{MaxLength: 2000, ReportThreshold: 3},
+		RateLimit:   RateLimit{RaterPerMinute: 30, RaterBurst: 10, IPPerMinute: 120, IPBurst: 60, RegistrationPerMinute: 10, RegistrationBurst: 5},
+		Abuse:       Abuse{Window: 10 * time.Minute, NewRaterThreshold: 20, ScanInterval: time.Minute},
+		BoxOffice:   BoxOffice{BreakerThreshold: 5, BreakerCooldown: 30 * time.Second},
+		Enrichment:  Enrichment{Interval: 30 * time.Second},
+		Backup:      Backup{Retention: 7},
+		Idempotency: Idempotency{KeyTTL: 24 * time.Hour, Lease: time.Minute},
+		Health:      Health{CacheTTL: 5 * time.Second},
+		Logging:     Logging{Level: "info", SampleRate: 1},
+		Tracing:     Tracing{Exporter: "none", ServiceName: "robin-camp"},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence: Default, the YAML file at path (skipped when
+// path is empty), non-empty environment variables, and overrides, which carry command-line flags.
+// The result is validated; every problem found is reported in the returned error.
+func Load(path string, overrides ...func(*Config)) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, &cfg); err != nil {
+			return nil, fmt.Errorf("parse config file %s: %w", path, err)
+		}
+	}
+	envErr := applyEnv(&cfg, os.LookupEnv)
+	for _, override := range overrides {
+		override(&cfg)
+	}
+	if err := errors.Join(envErr, cfg.Validate()); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return &cfg, nil
+}
+
+// errInvalid reports a single invalid setting as "key (ENV): problem".
+func errInvalid(key, env, format string, args ...any) error {
+	return fmt.Errorf("%s (%s): %s", key, env, fmt.Sprintf(format, args...))
+}
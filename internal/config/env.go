@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// redacted replaces the value of a non-empty secret in YAML output.
+const redacted = "[redacted]"
+
+// applyEnv sets every field whose env variable is present and non-empty. Values that do not parse are
+// errors rather than being ignored, so a typo cannot silently fall back to the default.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	var errs []error
+	walk(reflect.ValueOf(cfg).Elem(), "", func(key string, field reflect.StructField, v reflect.Value) {
+		name := field.Tag.Get("env")
+		raw, ok := lookup(name)
+		if name == "" || !ok || raw == "" {
+			return
+		}
+		if err := setValue(v, raw); err != nil {
+			errs = append(errs, errInvalid(key, name, "%v", err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func setValue(v reflect.Value, raw string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q (e.g. 30s, 5m)", raw)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q (want true or false)", raw)
+		}
+		v.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+// walk calls fn for every leaf field of the section structs in v, with its dotted YAML key.
+func walk(v reflect.Value, prefix string, fn func(key string, field reflect.StructField, v reflect.Value)) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := prefix + field.Tag.Get("yaml")
+		if field.Type.Kind() == reflect.Struct && field.Type != durationType {
+			walk(v.Field(i), key+".", fn)
+			continue
+		}
+		fn(key, field, v.Field(i))
+	}
+}
+
+// YAML renders the configuration in the config file format, with durations as Go duration strings
+// and every non-empty secret replaced by "[redacted]".
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(section(reflect.ValueOf(c).Elem()))
+}
+
+func section(v reflect.Value) yaml.MapSlice {
+	out := make(yaml.MapSlice, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		fv := v.Field(i)
+		var value any
+		switch {
+		case field.Type == durationType:
+			value = time.Duration(fv.Int()).String()
+		case field.Type.Kind() == reflect.Struct:
+			value = section(fv)
+		case field.Tag.Get("secret") == "true" && !fv.IsZero():
+			value = redacted
+		default:
+			value = fv.Interface()
+		}
+		out = append(out, yaml.MapItem{Key: field.Tag.Get("yaml"), Value: value})
+	}
+	return out
+}
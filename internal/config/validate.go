@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"time"
+
+	"Robin-Camp/internal/authz"
+	"Robin-Camp/internal/logging"
+	"Robin-Camp/internal/tracing"
+)
+
+// Validate checks every setting and returns all problems at once, one per line.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, env, format string, args ...any) {
+		if !ok {
+			errs = append(errs, errInvalid(key, env, format, args...))
+		}
+	}
+	nonNegative := func(d time.Duration, key, env string) {
+		check(d >= 0, key, env, "must not be negative")
+	}
+	fileExists := func(path, key, env string) {
+		if path == "" {
+			return
+		}
+		if _, err := os.Stat(path); err != nil {
+			errs = append(errs, errInvalid(key, env, "%v", err))
+		}
+	}
+
+	s := c.Server
+	check(s.Port > 0 && s.Port <= 65535, "server.port", "PORT", "must be between 1 and 65535, got %d", s.Port)
+	nonNegative(s.ReadTimeout, "server.readTimeout", "READ_TIMEOUT")
+	nonNegative(s.WriteTimeout, "server.writeTimeout", "WRITE_TIMEOUT")
+	nonNegative(s.IdleTimeout, "server.idleTimeout", "IDLE_TIMEOUT")
+	check(s.MaxBodySize > 0, "server.maxBodySize", "MAX_BODY_SIZE", "must be positive")
+	check(s.MaxJSONBodySize > 0 && s.MaxJSONBodySize <= s.MaxBodySize, "server.maxJSONBodySize", "MAX_JSON_BODY_SIZE",
+		"must be positive and at most server.maxBodySize (%d)", s.MaxBodySize)
+	if _, err := s.TrustedProxyCIDRs(); err != nil {
+		errs = append(errs, errInvalid("server.trustedProxies", "TRUSTED_PROXIES", "%v", err))
+	}
+	check(s.ShutdownTimeout > 0, "server.shutdownTimeout", "SHUTDOWN_TIMEOUT", "must be positive")
+	check(s.DrainDelay >= 0 && s.DrainDelay < s.ShutdownTimeout, "server.drainDelay", "SHUTDOWN_DRAIN_DELAY",
+		"must be at least 0 and shorter than server.shutdownTimeout (%s)", s.ShutdownTimeout)
+
+	t := c.TLS
+	check((t.CertFile == "") == (t.KeyFile == ""), "tls.keyFile", "TLS_KEY_FILE", "tls.certFile and tls.keyFile must be set together")
+	fileExists(t.CertFile, "tls.certFile", "TLS_CERT_FILE")
+	fileExists(t.KeyFile, "tls.keyFile", "TLS_KEY_FILE")
+	fileExists(t.ClientCAFile, "tls.clientCAFile", "TLS_CLIENT_CA_FILE")
+	check(t.ClientCAFile == "" || t.Enabled(), "tls.clientCAFile", "TLS_CLIENT_CA_FILE", "requires tls.certFile and tls.keyFile")
+	check(t.RedirectAddress == "" || t.Enabled(), "tls.redirectAddress", "TLS_REDIRECT_ADDRESS", "requires tls.certFile and tls.keyFile")
+	check(t.ReloadInterval > 0, "tls.reloadInterval", "TLS_RELOAD_INTERVAL", "must be positive")
+
+	check(c.Compression.MinSize >= 0, "compression.minSize", "COMPRESSION_MIN_SIZE", "must not be negative")
+
+	check(c.Database.URL != "", "database.url", "DB_URL", "is required")
+
+	a := c.Auth
+	fileExists(a.JWKSFile, "auth.jwksFile", "JWT_JWKS_FILE")
+	check(authz.KnownRole(a.AnonymousRole), "auth.anonymousRole", "RBAC_ANONYMOUS_ROLE", "unknown role %q", a.AnonymousRole)
+	check(authz.KnownRole(a.DefaultRaterRole), "auth.defaultRaterRole", "RBAC_DEFAULT_RATER_ROLE", "unknown role %q", a.DefaultRaterRole)
+
+	nonNegative(c.OAuth.TokenTTL, "oauth.tokenTTL", "OAUTH_TOKEN_TTL")
+
+	r := c.Raters
+	// A shared secret would let whoever mints one kind of token mint the other.
+	check(r.TokenSecret == "" || r.TokenSecret != a.JWTSecret, "raters.tokenSecret", "RATER_TOKEN_SECRET", "must differ from auth.jwtSecret")
+	check(r.TokenSecret == "" || r.TokenSecret != c.OAuth.SigningSecret, "raters.tokenSecret", "RATER_TOKEN_SECRET", "must differ from oauth.signingSecret")
+	switch r.AuthMode {
+	case authz.RaterAuthPlain, authz.RaterAuthCompat:
+	case authz.RaterAuthStrict:
+		check(r.TokenSecret != "", "raters.tokenSecret", "RATER_TOKEN_SECRET", "is required when raters.authMode is strict")
+	default:
+		check(false, "raters.authMode", "RATER_AUTH_MODE", "must be plain, compat or strict, got %q", r.AuthMode)
+	}
+
+	check(c.Reviews.MaxLength > 0, "reviews.maxLength", "REVIEW_MAX_LENGTH", "must be positive")
+	check(c.Reviews.ReportThreshold > 0, "reviews.reportThreshold", "MODERATION_REPORT_THRESHOLD", "must be positive")
+	fileExists(c.Reviews.Wordlist, "reviews.wordlist", "MODERATION_WORDLIST")
+
+	rl := c.RateLimit
+	check(rl.RaterPerMinute >= 0, "rateLimit.raterPerMinute", "RATE_LIMIT_RATER_PER_MINUTE", "must not be negative")
+	check(rl.RaterBurst >= 0, "rateLimit.raterBurst", "RATE_LIMIT_RATER_BURST", "must not be negative")
+	check(rl.IPPerMinute >= 0, "rateLimit.ipPerMinute", "RATE_LIMIT_IP_PER_MINUTE", "must not be negative")
+	check(rl.IPBurst >= 0, "rateLimit.ipBurst", "RATE_LIMIT_IP_BURST", "must not be negative")
+	check(rl.RegistrationPerMinute >= 0, "rateLimit.registrationPerMinute", "RATE_LIMIT_REGISTRATION_PER_MINUTE", "must not be negative")
+	check(rl.RegistrationBurst >= 0, "rateLimit.registrationBurst", "RATE_LIMIT_REGISTRATION_BURST", "must not be negative")
+
+	check(c.Abuse.Window > 0, "abuse.window", "ABUSE_WINDOW", "must be positive")
+	check(c.Abuse.NewRaterThreshold > 0, "abuse.newRaterThreshold", "ABUSE_NEW_RATER_THRESHOLD", "must be positive")
+	check(c.Abuse.ScanInterval > 0, "abuse.scanInterval", "ABUSE_SCAN_INTERVAL", "must be positive")
+
+	b := c.BoxOffice
+	switch {
+	case b.URL == "" && b.APIKey != "":
+		check(false, "boxOffice.url", "BOXOFFICE_URL", "is required when boxOffice.apiKey is set")
+	case b.URL != "" && b.APIKey == "":
+		check(false, "boxOffice.apiKey", "BOXOFFICE_API_KEY", "is required when boxOffice.url is set")
+	case b.URL != "":
+		u, err := url.Parse(b.URL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"boxOffice.url", "BOXOFFICE_URL", "must be an absolute http(s) URL, got %q", b.URL)
+	}
+	check(b.BreakerThreshold >= 0, "boxOffice.breakerThreshold", "BOXOFFICE_BREAKER_THRESHOLD", "must not be negative")
+	check(b.BreakerCooldown > 0, "boxOffice.breakerCooldown", "BOXOFFICE_BREAKER_COOLDOWN", "must be positive")
+
+	check(c.Enrichment.Interval > 0, "enrichment.interval", "ENRICHMENT_INTERVAL", "must be positive")
+	check(c.Backup.Retention >= 0, "backup.retention", "BACKUP_RETENTION", "must not be negative")
+	check(c.Idempotency.KeyTTL > 0, "idempotency.keyTTL", "IDEMPOTENCY_KEY_TTL", "must be positive")
+	check(c.Idempotency.Lease > 0, "idempotency.lease", "IDEMPOTENCY_LEASE", "must be positive")
+	check(c.Health.CacheTTL > 0, "health.cacheTTL", "HEALTH_CACHE_TTL", "must be positive")
+
+	if _, err := logging.ParseLevel(c.Logging.Level); err != nil {
+		errs = append(errs, errInvalid("logging.level", "LOG_LEVEL", "%v", err))
+	}
+	check(c.Logging.SampleRate >= 0 && c.Logging.SampleRate <= 1, "logging.sampleRate", "LOG_SAMPLE_RATE",
+		"must be between 0 and 1, got %g", c.Logging.SampleRate)
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	default:
+		check(false, "tracing.exporter", "OTEL_TRACES_EXPORTER", "must be %s, %s or %s, got %q",
+			tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, c.Tracing.Exporter)
+	}
+	check(c.Tracing.ServiceName != "", "tracing.serviceName", "OTEL_SERVICE_NAME", "is required")
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"Robin-Camp/internal/authz"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{name: "defaults", modify: func(*Config) {}},
+		{name: "no anonymous access", modify: func(c *Config) { c.Auth.AnonymousRole = authz.RoleNone }},
+		{name: "unknown anonymous role", modify: func(c *Config) { c.Auth.AnonymousRole = "root" }, want: "auth.anonymousRole (RBAC_ANONYMOUS_ROLE)"},
+		{name: "unknown default rater role", modify: func(c *Config) { c.Auth.DefaultRaterRole = "" }, want: "auth.defaultRaterRole (RBAC_DEFAULT_RATER_ROLE)"},
+		{name: "unknown rater auth mode", modify: func(c *Config) { c.Raters.AuthMode = "lax" }, want: "raters.authMode (RATER_AUTH_MODE)"},
+		{name: "rater secret reuses the JWT secret", modify: func(c *Config) { c.Raters.TokenSecret, c.Auth.JWTSecret = "s3cret", "s3cret" }, want: "must differ from auth.jwtSecret"},
+		{name: "rater secret reuses the OAuth secret", modify: func(c *Config) { c.Raters.TokenSecret, c.OAuth.SigningSecret = "s3cret", "s3cret" }, want: "must differ from oauth.signingSecret"},
+		{name: "distinct secrets", modify: func(c *Config) {
+			c.Raters.TokenSecret, c.Auth.JWTSecret, c.OAuth.SigningSecret = "rater", "bearer", "oauth"
+		}},
+		{name: "strict rater auth without a secret", modify: func(c *Config) { c.Raters.AuthMode = authz.RaterAuthStrict }, want: "raters.tokenSecret (RATER_TOKEN_SECRET)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want an error for %s", err, tt.want)
+			}
+		})
+	}
+}
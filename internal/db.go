@@ -1,25 +1,38 @@
 package internal
 
 import (
+	"Robin-Camp/internal/metrics"
+	"Robin-Camp/internal/tracing"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
-	_ "modernc.org/sqlite"
+	"modernc.org/sqlite"
 )
 
-var DB *sql.DB
+// sqliteDriver is the SQLite driver wrapped to report statement latency to /metrics and to trace queries.
+const sqliteDriver = "sqlite-instrumented"
 
-func InitDB() {
-	var err error
-	dsn := os.Getenv("DB_URL")
-	DB, err = initDB(context.Background(), dsn)
+func init() {
+	sql.Register(sqliteDriver, tracing.WrapDriver(metrics.WrapDriver(&sqlite.Driver{})))
+}
+
+// OpenDB opens the database at dsn and applies pending migrations. For a database file it also takes the shared
+// lock that keeps RestoreBackup from replacing the file underneath this process until CloseDB.
+func OpenDB(dsn string) (*sql.DB, error) {
+	if path, err := DatabasePath(dsn); err == nil {
+		if dbLock, err = lockDatabase(path, false); err != nil {
+			return nil, fmt.Errorf("%w (is a restore running?)", err)
+		}
+	}
+	db, err := initDB(context.Background(), dsn)
 	if err != nil {
-		panic(fmt.Sprintf("failed to initialize database: %v", err))
+		releaseDatabaseLock()
+		return nil, err
 	}
+	return db, nil
 }
 
 var pragmas = []string{
@@ -62,6 +75,237 @@ var migrationStatements = []string{
         FOREIGN KEY(movie_id) REFERENCES movies(id) ON DELETE CASCADE
     )`,
 	`CREATE INDEX IF NOT EXISTS idx_ratings_movie ON ratings(movie_id)`,
+	`CREATE INDEX IF NOT EXISTS idx_ratings_updated_at ON ratings(updated_at)`,
+	`CREATE TABLE IF NOT EXISTS rating_events (
+        id INTEGER PRIMARY KEY AUTOINCREMENT,
+        movie_id TEXT NOT NULL,
+        rater_id TEXT NOT NULL,
+        action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+        old_rating REAL,
+        new_rating REAL,
+        client_ip TEXT,
+        created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now'))
+    )`,
+	`CREATE INDEX IF NOT EXISTS idx_rating_events_movie ON rating_events(movie_id, id)`,
+	`CREATE INDEX IF NOT EXISTS idx_rating_events_rater ON rating_events(rater_id, id)`,
+	`CREATE INDEX IF NOT EXISTS idx_rating_events_created_at ON rating_events(created_at)`,
+	`CREATE TABLE IF NOT EXISTS flagged_raters (
+        rater_id TEXT PRIMARY KEY,
+        movie_id TEXT NOT NULL,
+        reason TEXT NOT NULL,
+        flagged_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now'))
+    )`,
+	`CREATE TRIGGER IF NOT EXISTS rating_events_no_update BEFORE UPDATE ON rating_events
+    BEGIN
+        SELECT RAISE(ABORT, 'rating_events is append-only');
+    END`,
+	`CREATE TRIGGER IF NOT EXISTS rating_events_no_delete BEFORE DELETE ON rating_events
+    BEGIN
+        SELECT RAISE(ABORT, 'rating_events is append-only');
+    END`,
+}
+
+// schemaMigrations run after migrationStatements for changes that cannot be expressed idempotently
+// (e.g. ALTER TABLE). PRAGMA user_version records how many steps have been applied.
+var schemaMigrations = [][]string{
+	// 1: optional text reviews stored alongside the rating row, plus helpful votes.
+	{
+		`ALTER TABLE ratings ADD COLUMN review_title TEXT`,
+		`ALTER TABLE ratings ADD COLUMN review_body TEXT`,
+		`ALTER TABLE ratings ADD COLUMN review_spoiler INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE ratings ADD COLUMN review_language TEXT`,
+		`ALTER TABLE ratings ADD COLUMN review_updated_at TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_ratings_review_updated_at ON ratings(movie_id, review_updated_at)`,
+		`CREATE TABLE IF NOT EXISTS review_votes (
+            movie_id TEXT NOT NULL,
+            rater_id TEXT NOT NULL,
+            voter_id TEXT NOT NULL,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            PRIMARY KEY (movie_id, rater_id, voter_id),
+            FOREIGN KEY(movie_id, rater_id) REFERENCES ratings(movie_id, rater_id) ON DELETE CASCADE
+        )`,
+	},
+	// 2: moderation state on ratings and user reports.
+	{
+		`ALTER TABLE ratings ADD COLUMN moderation_status TEXT NOT NULL DEFAULT 'visible' CHECK (moderation_status IN ('visible', 'pending', 'approved', 'rejected'))`,
+		`ALTER TABLE ratings ADD COLUMN moderation_reason TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_ratings_moderation_status ON ratings(moderation_status)`,
+		`CREATE TABLE IF NOT EXISTS moderation_reports (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            movie_id TEXT NOT NULL,
+            rater_id TEXT NOT NULL,
+            reporter_id TEXT NOT NULL,
+            reason TEXT,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            UNIQUE (movie_id, rater_id, reporter_id),
+            FOREIGN KEY(movie_id, rater_id) REFERENCES ratings(movie_id, rater_id) ON DELETE CASCADE
+        )`,
+	},
+	// 3: named API keys and the writer that created each movie.
+	{
+		`CREATE TABLE IF NOT EXISTS api_keys (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL UNIQUE,
+            key_hash TEXT NOT NULL,
+            scopes TEXT NOT NULL DEFAULT '',
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            last_used_at TEXT,
+            expires_at TEXT,
+            revoked_at TEXT
+        )`,
+		`ALTER TABLE movies ADD COLUMN created_by TEXT`,
+	},
+	// 4: OAuth2 client-credentials clients and revoked access tokens.
+	{
+		`CREATE TABLE IF NOT EXISTS oauth_clients (
+            client_id TEXT PRIMARY KEY,
+            name TEXT NOT NULL UNIQUE,
+            secret_hash TEXT NOT NULL,
+            scopes TEXT NOT NULL DEFAULT '',
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            revoked_at TEXT
+        )`,
+		`CREATE TABLE IF NOT EXISTS oauth_revoked_tokens (
+            jti TEXT PRIMARY KEY,
+            expires_at TEXT NOT NULL
+        )`,
+	},
+	// 5: role assignments for bearer principals and raters.
+	{
+		`CREATE TABLE IF NOT EXISTS role_assignments (
+            subject_kind TEXT NOT NULL CHECK (subject_kind IN ('principal', 'rater')),
+            subject TEXT NOT NULL,
+            role TEXT NOT NULL,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            PRIMARY KEY (subject_kind, subject, role)
+        )`,
+	},
+	// 6: tenants. movies is rebuilt so that title uniqueness becomes per tenant; existing rows join the default tenant.
+	{
+		`CREATE TABLE IF NOT EXISTS tenants (
+            id TEXT PRIMARY KEY,
+            name TEXT NOT NULL,
+            boxoffice_url TEXT,
+            boxoffice_api_key TEXT,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now'))
+        )`,
+		`INSERT OR IGNORE INTO tenants (id, name) VALUES ('default', 'Default')`,
+		`CREATE TABLE IF NOT EXISTS tenant_hosts (
+            host TEXT PRIMARY KEY,
+            tenant_id TEXT NOT NULL,
+            FOREIGN KEY(tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+        )`,
+		`CREATE TABLE movies_new (
+            id TEXT PRIMARY KEY,
+            tenant_id TEXT NOT NULL DEFAULT 'default',
+            title TEXT NOT NULL,
+            release_date TEXT NOT NULL,
+            genre TEXT NOT NULL,
+            distributor TEXT,
+            budget INTEGER,
+            mpa_rating TEXT,
+            created_by TEXT,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            updated_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            UNIQUE (tenant_id, title),
+            FOREIGN KEY(tenant_id) REFERENCES tenants(id)
+        )`,
+		`INSERT INTO movies_new (id, tenant_id, title, release_date, genre, distributor, budget, mpa_rating, created_by, created_at, updated_at)
+            SELECT id, 'default', title, release_date, genre, distributor, budget, mpa_rating, created_by, created_at, updated_at FROM movies`,
+		`DROP TABLE movies`,
+		`ALTER TABLE movies_new RENAME TO movies`,
+		`CREATE INDEX IF NOT EXISTS idx_movies_release_date ON movies(release_date)`,
+		`CREATE INDEX IF NOT EXISTS idx_movies_genre ON movies(genre)`,
+		`CREATE INDEX IF NOT EXISTS idx_movies_created_at ON movies(created_at)`,
+		`ALTER TABLE box_office ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+		`ALTER TABLE ratings ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default'`,
+		`CREATE INDEX IF NOT EXISTS idx_ratings_tenant_updated_at ON ratings(tenant_id, updated_at)`,
+	},
+	// 7: stored responses for Idempotency-Key retries.
+	{
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+            scope TEXT NOT NULL,
+            key TEXT NOT NULL,
+            request_hash TEXT NOT NULL,
+            status_code INTEGER NOT NULL DEFAULT 0,
+            content_type TEXT,
+            location TEXT,
+            body BLOB,
+            expires_at TEXT NOT NULL,
+            PRIMARY KEY (scope, key)
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at)`,
+	},
+	// 8: queued box office lookups for imported movies.
+	{
+		`CREATE TABLE IF NOT EXISTS enrichment_jobs (
+            movie_id TEXT PRIMARY KEY,
+            tenant_id TEXT NOT NULL,
+            title TEXT NOT NULL,
+            attempts INTEGER NOT NULL DEFAULT 0,
+            last_error TEXT,
+            next_attempt_at TEXT NOT NULL,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            FOREIGN KEY(movie_id) REFERENCES movies(id) ON DELETE CASCADE
+        )`,
+		`CREATE INDEX IF NOT EXISTS idx_enrichment_jobs_next_attempt_at ON enrichment_jobs(next_attempt_at)`,
+	},
+	// 9: single row rewritten by the /readyz write probe.
+	{
+		`CREATE TABLE IF NOT EXISTS health_probe (
+            id INTEGER PRIMARY KEY CHECK (id = 1),
+            checked_at TEXT NOT NULL
+        )`,
+	},
+	// 10: rater IDs an admin issued a token for; compat mode no longer accepts them as plain X-Rater-Id values.
+	{
+		`CREATE TABLE IF NOT EXISTS rater_tokens (
+            rater_id TEXT PRIMARY KEY,
+            issued_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now'))
+        )`,
+	},
+	// 11: API keys and OAuth clients bound to a single tenant; NULL keeps them on the default tenant.
+	{
+		`ALTER TABLE api_keys ADD COLUMN tenant_id TEXT`,
+		`ALTER TABLE oauth_clients ADD COLUMN tenant_id TEXT`,
+	},
+	// 12: when moderation or an abuse flag last changed whether a rating counts, so aggregates can revalidate.
+	{
+		`ALTER TABLE ratings ADD COLUMN moderated_at TEXT`,
+	},
+	// 13: abuse flags and role assignments become per tenant. A flag moves to the tenant of the movie that
+	// triggered it; existing role assignments are copied into every tenant so that nobody loses access.
+	{
+		`CREATE TABLE flagged_raters_new (
+            tenant_id TEXT NOT NULL DEFAULT 'default',
+            rater_id TEXT NOT NULL,
+            movie_id TEXT NOT NULL,
+            reason TEXT NOT NULL,
+            flagged_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            PRIMARY KEY (tenant_id, rater_id)
+        )`,
+		`INSERT INTO flagged_raters_new (tenant_id, rater_id, movie_id, reason, flagged_at)
+            SELECT COALESCE((SELECT tenant_id FROM movies WHERE id = f.movie_id), 'default'), rater_id, movie_id, reason, flagged_at FROM flagged_raters f`,
+		`DROP TABLE flagged_raters`,
+		`ALTER TABLE flagged_raters_new RENAME TO flagged_raters`,
+		`CREATE TABLE role_assignments_new (
+            tenant_id TEXT NOT NULL DEFAULT 'default',
+            subject_kind TEXT NOT NULL CHECK (subject_kind IN ('principal', 'rater')),
+            subject TEXT NOT NULL,
+            role TEXT NOT NULL,
+            created_at TEXT NOT NULL DEFAULT (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')),
+            PRIMARY KEY (tenant_id, subject_kind, subject, role)
+        )`,
+		`INSERT INTO role_assignments_new (tenant_id, subject_kind, subject, role, created_at)
+            SELECT t.id, r.subject_kind, r.subject, r.role, r.created_at FROM role_assignments r CROSS JOIN tenants t`,
+		`DROP TABLE role_assignments`,
+		`ALTER TABLE role_assignments_new RENAME TO role_assignments`,
+	},
+}
+
+// SchemaVersion is the user_version a fully migrated database reports.
+func SchemaVersion() int {
+	return len(schemaMigrations)
 }
 
 func initDB(ctx context.Context, dsn string) (*sql.DB, error) {
@@ -69,7 +313,7 @@ func initDB(ctx context.Context, dsn string) (*sql.DB, error) {
 		return nil, errors.New("sqlite dsn is empty")
 	}
 
-	db, err := sql.Open("sqlite", dsn)
+	db, err := sql.Open(sqliteDriver, dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
@@ -101,6 +345,13 @@ func applyPragmas(ctx context.Context, db *sql.DB) error {
 }
 
 func runMigrations(ctx context.Context, db *sql.DB) error {
+	// Table rebuilds must not cascade deletes into referencing tables, so foreign keys are off while
+	// migrating (the pool holds a single connection) and verified with foreign_key_check before commit.
+	if _, err := db.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return fmt.Errorf("disable foreign keys: %w", err)
+	}
+	defer db.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("begin migration tx: %w", err)
@@ -113,6 +364,39 @@ func runMigrations(ctx context.Context, db *sql.DB) error {
 		}
 	}
 
+	var version int
+	if err := tx.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if version > len(schemaMigrations) {
+		tx.Rollback()
+		return fmt.Errorf("database schema version %d is newer than supported version %d", version, len(schemaMigrations))
+	}
+	for i := version; i < len(schemaMigrations); i++ {
+		for _, stmt := range schemaMigrations[i] {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("execute migration %d: %w", i+1, err)
+			}
+		}
+	}
+	// PRAGMA does not accept bound parameters.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, len(schemaMigrations))); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("record schema version: %w", err)
+	}
+
+	var violations int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM pragma_foreign_key_check`).Scan(&violations); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("check foreign keys: %w", err)
+	}
+	if violations > 0 {
+		tx.Rollback()
+		return fmt.Errorf("migrations left %d foreign key violations", violations)
+	}
+
 	if err := tx.Commit(); err != nil {
 		return fmt.Errorf("commit migrations: %w", err)
 	}
@@ -120,8 +404,29 @@ func runMigrations(ctx context.Context, db *sql.DB) error {
 	return nil
 }
 
+// CloseDB folds the WAL back into the database file and closes db, leaving a single self-contained file.
+func CloseDB(ctx context.Context, db *sql.DB) error {
+	defer releaseDatabaseLock()
+	if _, err := db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		db.Close()
+		return fmt.Errorf("checkpoint wal: %w", err)
+	}
+	return db.Close()
+}
+
+func releaseDatabaseLock() {
+	if dbLock != nil {
+		dbLock.Close()
+		dbLock = nil
+	}
+}
+
 // WithTx provides a helper for running code inside a transaction with shared settings.
-func WithTx(ctx context.Context, db *sql.DB, fn func(context.Context, *sql.Tx) error) error {
+// The transaction is traced as a db.transaction span that parents the queries fn runs.
+func WithTx(ctx context.Context, db *sql.DB, fn func(context.Context, *sql.Tx) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.transaction")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
 		return fmt.Errorf("begin tx: %w", err)
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrDatabaseInUse is returned when another process holds the database lock that the operation conflicts with.
+var ErrDatabaseInUse = errors.New("database is in use by another process")
+
+// dbLock is the shared lock OpenDB holds on the database until CloseDB.
+var dbLock *os.File
+
+// lockDatabase locks the file beside the database at dbPath. Processes that open the database hold a shared
+// lock; a restore needs the exclusive lock, so it is refused while the server or another command is running.
+func lockDatabase(dbPath string, exclusive bool) (*os.File, error) {
+	f, err := os.OpenFile(dbPath+".lock", os.O_CREATE|os.O_RDWR, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("open database lock: %w", err)
+	}
+	if err := lockFile(f, exclusive); err != nil {
+		f.Close()
+		if errors.Is(err, ErrDatabaseInUse) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lock database: %w", err)
+	}
+	return f, nil
+}
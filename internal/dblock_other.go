@@ -0,0 +1,10 @@
+//go:build !unix
+
+package internal
+
+import "os"
+
+// lockFile is a no-op where flock is unavailable; restores there rely on the server having been stopped.
+func lockFile(f *os.File, exclusive bool) error {
+	return nil
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package internal
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes a non-blocking advisory lock on f, shared or exclusive. The lock is released when f is closed.
+func lockFile(f *os.File, exclusive bool) error {
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	err := syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return ErrDatabaseInUse
+	}
+	return err
+}
@@ -0,0 +1,160 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"Robin-Camp/internal/boxoffice"
+	"Robin-Camp/internal/tracing"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// maxEnrichmentAttempts is how often a job is tried before it stays in the queue as failed.
+	maxEnrichmentAttempts = 5
+	// enrichmentBatchSize caps the number of upstream lookups per worker pass.
+	enrichmentBatchSize = 20
+)
+
+// enrichmentJob is a queued box office lookup for a movie created without one.
+type enrichmentJob struct {
+	MovieID  string
+	TenantID string
+	Title    string
+	Attempts int
+}
+
+// enqueueEnrichment queues a box office lookup for the movie; queuing an already queued movie is a no-op.
+func enqueueEnrichment(ctx context.Context, tx *sql.Tx, movieID, tenantID, title string) error {
+	if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO enrichment_jobs (movie_id, tenant_id, title, next_attempt_at) VALUES (?, ?, ?, (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')))`, movieID, tenantID, title); err != nil {
+		return fmt.Errorf("enqueue enrichment: %w", err)
+	}
+	return nil
+}
+
+// wakeEnrichment asks the worker to run a pass now instead of at the next tick.
+func (h *Handler) wakeEnrichment() {
+	select {
+	case h.enrichmentWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunEnrichmentWorker drains the box office enrichment queue every interval, or sooner when new jobs
+// are queued, until ctx is cancelled.
+func (h *Handler) RunEnrichmentWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.enrichmentWake:
+		}
+		for {
+			n, err := h.processEnrichmentJobs(ctx, time.Now().UTC())
+			if err != nil {
+				hlog.Errorf("enrichment worker: %v", err)
+			}
+			if err != nil || n < enrichmentBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+// processEnrichmentJobs runs up to enrichmentBatchSize due jobs and returns how many it picked up.
+// Upstream failures are retried with exponential backoff; a movie the upstream does not know is dropped from the queue.
+func (h *Handler) processEnrichmentJobs(ctx context.Context, now time.Time) (int, error) {
+	jobs, err := dueEnrichmentJobs(ctx, h.db, now)
+	if err != nil {
+		return 0, err
+	}
+	for i, job := range jobs {
+		// On shutdown the job in hand is finished and the rest stay queued for the next start.
+		if ctx.Err() != nil {
+			return i, nil
+		}
+		if err := h.runEnrichmentJob(context.WithoutCancel(ctx), job, now); err != nil {
+			// While the upstream circuit is open the remaining jobs stay due, without using up attempts.
+			if errors.Is(err, boxoffice.ErrCircuitOpen) {
+				return i, nil
+			}
+			return 0, fmt.Errorf("finish enrichment job %s: %w", job.MovieID, err)
+		}
+	}
+	return len(jobs), nil
+}
+
+// runEnrichmentJob looks up one movie and records the result, as its own trace.
+func (h *Handler) runEnrichmentJob(ctx context.Context, job enrichmentJob, now time.Time) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "enrichment.job", trace.WithNewRoot(),
+		trace.WithAttributes(attribute.String("movie.id", job.MovieID), attribute.Int("enrichment.attempts", job.Attempts)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	box, lookupErr := h.lookupBoxOffice(ctx, job.TenantID, job.Title)
+	if errors.Is(lookupErr, boxoffice.ErrCircuitOpen) {
+		return lookupErr
+	}
+	return WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		if lookupErr != nil {
+			backoff := time.Duration(1<<job.Attempts) * time.Minute
+			_, err := tx.ExecContext(ctx, `UPDATE enrichment_jobs SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE movie_id = ?`,
+				lookupErr.Error(), now.Add(backoff).Format(sqliteTimeLayout), job.MovieID,
+			)
+			return err
+		}
+		if box != nil {
+			if err := storeBoxOffice(ctx, tx, job.MovieID, job.TenantID, box); err != nil {
+				return err
+			}
+		}
+		_, err := tx.ExecContext(ctx, `DELETE FROM enrichment_jobs WHERE movie_id = ?`, job.MovieID)
+		return err
+	})
+}
+
+// lookupBoxOffice fetches box office data for the title. A nil result with a nil error means the upstream
+// has no data for it or no client is configured, so there is nothing to retry.
+func (h *Handler) lookupBoxOffice(ctx context.Context, tenantID, title string) (*BoxOffice, error) {
+	client, err := h.boxClientFor(ctx, tenantID)
+	if err != nil || client == nil {
+		return nil, err
+	}
+	bo, err := client.GetMovieBoxOffice(ctx, title)
+	if err != nil {
+		if errors.Is(err, boxoffice.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return toBoxOffice(bo), nil
+}
+
+func dueEnrichmentJobs(ctx context.Context, db *sql.DB, now time.Time) ([]enrichmentJob, error) {
+	rows, err := db.QueryContext(ctx, `SELECT movie_id, tenant_id, title, attempts FROM enrichment_jobs WHERE attempts < ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ?`,
+		maxEnrichmentAttempts, now.Format(sqliteTimeLayout), enrichmentBatchSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load enrichment jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []enrichmentJob
+	for rows.Next() {
+		var j enrichmentJob
+		if err := rows.Scan(&j.MovieID, &j.TenantID, &j.Title, &j.Attempts); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
@@ -0,0 +1,25 @@
+package internal
+
+import (
+	"Robin-Camp/internal/logging"
+	"context"
+	"net/http"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// internalErrorMessage stands in for the error text in 500 responses, which may describe SQL or file paths.
+const internalErrorMessage = "Internal server error; quote the requestId when reporting this problem"
+
+// writeError sends e with the given status, tagged with the request ID.
+func writeError(c *app.RequestContext, status int, e Error) {
+	e.RequestID = logging.RequestID(c)
+	c.JSON(status, e)
+}
+
+// internalError logs err with the request ID and answers 500 without revealing err to the client.
+func internalError(ctx context.Context, c *app.RequestContext, err error) {
+	hlog.CtxErrorf(ctx, "%s %s: %v", c.Method(), c.Path(), err)
+	writeError(c, http.StatusInternalServerError, Error{Code: "INTERNAL", Message: internalErrorMessage})
+}
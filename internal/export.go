@@ -0,0 +1,360 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"Robin-Camp/internal/compress"
+	"Robin-Camp/internal/https"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/http1/resp"
+)
+
+// Output formats accepted by ExportMovies and ExportRatings.
+const (
+	ExportFormatJSONL = "jsonl"
+	ExportFormatCSV   = "csv"
+)
+
+// exportChunkSize is the number of rows read per query. Exports page through the table by key and encode each
+// chunk into memory, writing it out only after the rows are closed, so a slow client never holds the single
+// pooled connection.
+const exportChunkSize = 500
+
+// ErrExportFormat is returned for an unknown export format.
+var ErrExportFormat = errors.New("invalid export format")
+
+// MovieExportFilter narrows ExportMovies; empty fields match everything.
+type MovieExportFilter struct {
+	TenantID string
+	Genre    string
+	// Year matches the YYYY prefix of releaseDate.
+	Year string
+	// UpdatedSince keeps movies updated at or after this timestamp.
+	UpdatedSince string
+}
+
+// RatingExportFilter narrows ExportRatings; empty fields match everything.
+type RatingExportFilter struct {
+	TenantID     string
+	Title        string
+	RaterID      string
+	UpdatedSince string
+}
+
+var movieExportColumns = []string{"id", "title", "releaseDate", "genre", "distributor", "budget", "mpaRating", "createdBy", "boxOfficeCurrency", "boxOfficeSource", "boxOfficeLastUpdated", "revenueWorldwide", "revenueOpeningWeekendUsa"}
+
+var ratingExportColumns = []string{"movieId", "movieTitle", "raterId", "rating", "reviewTitle", "reviewBody", "reviewSpoiler", "reviewLanguage", "moderationStatus", "updatedAt"}
+
+// ExportMovies writes the tenant's movies with their box office data to w as JSON Lines or CSV, ordered by ID.
+// It returns the number of movies written.
+func ExportMovies(ctx context.Context, db *sql.DB, w io.Writer, format string, f MovieExportFilter) (int, error) {
+	enc, err := newExportEncoder(w, format, movieExportColumns)
+	if err != nil {
+		return 0, err
+	}
+	if f.TenantID == "" {
+		f.TenantID = DefaultTenant
+	}
+
+	where := []string{"m.tenant_id = ?"}
+	args := []any{f.TenantID}
+	if f.Genre != "" {
+		where = append(where, "m.genre = ?")
+		args = append(args, f.Genre)
+	}
+	if f.Year != "" {
+		where = append(where, "substr(m.release_date,1,4) = ?")
+		args = append(args, f.Year)
+	}
+	if f.UpdatedSince != "" {
+		where = append(where, "m.updated_at >= ?")
+		args = append(args, f.UpdatedSince)
+	}
+	query := movieSelect + " WHERE " + strings.Join(where, " AND ") + " AND m.id > ? ORDER BY m.id LIMIT ?"
+
+	total := 0
+	lastID := ""
+	for {
+		n := 0
+		err := queryEach(ctx, db, query, append(args, lastID, exportChunkSize), func(rows *sql.Rows) error {
+			m, err := scanMovie(rows)
+			if err != nil {
+				return err
+			}
+			n++
+			lastID = m.ID
+			return enc.encode(m, movieCSVRecord(m))
+		})
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if err := enc.flush(); err != nil {
+			return total, err
+		}
+		if n < exportChunkSize {
+			return total, nil
+		}
+	}
+}
+
+// ExportRatings writes the tenant's ratings, including reviews and moderation status, to w as JSON Lines or CSV.
+// It returns the number of ratings written.
+func ExportRatings(ctx context.Context, db *sql.DB, w io.Writer, format string, f RatingExportFilter) (int, error) {
+	enc, err := newExportEncoder(w, format, ratingExportColumns)
+	if err != nil {
+		return 0, err
+	}
+	if f.TenantID == "" {
+		f.TenantID = DefaultTenant
+	}
+
+	where := []string{"r.tenant_id = ?"}
+	args := []any{f.TenantID}
+	if f.Title != "" {
+		where = append(where, "m.title = ?")
+		args = append(args, f.Title)
+	}
+	if f.RaterID != "" {
+		where = append(where, "r.rater_id = ?")
+		args = append(args, f.RaterID)
+	}
+	if f.UpdatedSince != "" {
+		where = append(where, "r.updated_at >= ?")
+		args = append(args, f.UpdatedSince)
+	}
+	query := `SELECT r.movie_id, m.title, r.rater_id, r.rating, r.review_title, r.review_body, r.review_spoiler, r.review_language, r.moderation_status, r.updated_at FROM ratings r JOIN movies m ON m.id = r.movie_id WHERE ` +
+		strings.Join(where, " AND ") + ` AND (r.movie_id, r.rater_id) > (?, ?) ORDER BY r.movie_id, r.rater_id LIMIT ?`
+
+	total := 0
+	lastMovie, lastRater := "", ""
+	for {
+		n := 0
+		err := queryEach(ctx, db, query, append(args, lastMovie, lastRater, exportChunkSize), func(rows *sql.Rows) error {
+			var r RatingExport
+			if err := rows.Scan(&r.MovieID, &r.MovieTitle, &r.RaterID, &r.Rating, &r.ReviewTitle, &r.ReviewBody, &r.ReviewSpoiler, &r.ReviewLanguage, &r.ModerationStatus, &r.UpdatedAt); err != nil {
+				return err
+			}
+			n++
+			lastMovie, lastRater = r.MovieID, r.RaterID
+			return enc.encode(r, ratingCSVRecord(&r))
+		})
+		if err != nil {
+			return total, err
+		}
+		total += n
+		if err := enc.flush(); err != nil {
+			return total, err
+		}
+		if n < exportChunkSize {
+			return total, nil
+		}
+	}
+}
+
+// queryEach runs the query and calls fn for every row. The rows are closed when it returns.
+func queryEach(ctx context.Context, db *sql.DB, query string, args []any, fn func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func movieCSVRecord(m *Movie) []string {
+	rec := []string{m.ID, m.Title, m.ReleaseDate, m.Genre, deref(m.Distributor), formatOptionalInt(m.Budget), deref(m.MpaRating), deref(m.CreatedBy), "", "", "", "", ""}
+	if bo := m.BoxOffice; bo != nil {
+		rec[8], rec[9], rec[10] = bo.Currency, bo.Source, bo.LastUpdated
+		rec[11] = strconv.FormatInt(bo.Revenue.Worldwide, 10)
+		rec[12] = formatOptionalInt(bo.Revenue.OpeningWeekendUsa)
+	}
+	return rec
+}
+
+func ratingCSVRecord(r *RatingExport) []string {
+	return []string{r.MovieID, r.MovieTitle, r.RaterID, strconv.FormatFloat(r.Rating, 'f', -1, 64), deref(r.ReviewTitle), deref(r.ReviewBody), strconv.FormatBool(r.ReviewSpoiler), deref(r.ReviewLanguage), r.ModerationStatus, r.UpdatedAt}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatOptionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.FormatInt(*v, 10)
+}
+
+// exportEncoder encodes one record per line as JSON or CSV into an in-memory chunk that flush writes out.
+type exportEncoder struct {
+	w    io.Writer
+	buf  *bytes.Buffer
+	json *json.Encoder
+	csv  *csv.Writer
+}
+
+func newExportEncoder(w io.Writer, format string, columns []string) (*exportEncoder, error) {
+	buf := new(bytes.Buffer)
+	switch format {
+	case ExportFormatJSONL, "":
+		return &exportEncoder{w: w, buf: buf, json: json.NewEncoder(buf)}, nil
+	case ExportFormatCSV:
+		enc := &exportEncoder{w: w, buf: buf, csv: csv.NewWriter(buf)}
+		if err := enc.csv.Write(columns); err != nil {
+			return nil, err
+		}
+		return enc, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrExportFormat, format)
+	}
+}
+
+func (e *exportEncoder) encode(v any, record []string) error {
+	if e.csv != nil {
+		return e.csv.Write(record)
+	}
+	return e.json.Encode(v)
+}
+
+// flush writes the encoded chunk to the underlying writer. Callers run it once the chunk's rows are closed.
+func (e *exportEncoder) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	_, err := e.w.Write(e.buf.Bytes())
+	e.buf.Reset()
+	return err
+}
+
+// exportFormat picks the format from ?format= or, failing that, the Accept header.
+func exportFormat(c *app.RequestContext) string {
+	if f := c.Query("format"); f != "" {
+		return f
+	}
+	if strings.Contains(string(c.GetHeader("Accept")), "text/csv") {
+		return ExportFormatCSV
+	}
+	return ExportFormatJSONL
+}
+
+// streamExport switches the response to chunked transfer encoding (or HTTP/2 data frames) and runs export against it.
+// Errors after the first chunk cannot change the status code, so they end the stream early and are logged.
+func streamExport(ctx context.Context, c *app.RequestContext, name, format string, export func(w io.Writer) (int, error)) {
+	if format != ExportFormatJSONL && format != ExportFormatCSV {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "format must be jsonl or csv"})
+		return
+	}
+	contentType := "application/x-ndjson"
+	if format == ExportFormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.SetContentType(contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	if !https.HijackStream(c) {
+		c.Response.HijackWriter(resp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
+	}
+
+	w := &flushingWriter{c: c, w: compress.NewStreamWriter(c, c)}
+	if _, err := export(w); err != nil {
+		hlog.CtxErrorf(ctx, "export %s: %v", name, err)
+	}
+	if err := w.Close(); err != nil {
+		hlog.CtxErrorf(ctx, "export %s: %v", name, err)
+	}
+}
+
+// flushingWriter sends every write to the client immediately through the negotiated compression;
+// the export encoder already batches per chunk.
+type flushingWriter struct {
+	c *app.RequestContext
+	w io.WriteCloser
+}
+
+func (w *flushingWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, w.c.Flush()
+}
+
+// Close ends the compressed stream and sends its trailer.
+func (w *flushingWriter) Close() error {
+	if err := w.w.Close(); err != nil {
+		return err
+	}
+	return w.c.Flush()
+}
+
+// exportMovies godoc
+// @Summary      Export movies
+// @Description  Streams every movie of the tenant with its box office data as JSON Lines (default) or CSV, ordered by ID. The format comes from the format parameter or the Accept header.
+// @Tags         Export
+// @Produce      json
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        format        query     string  false  "Output format"  Enums(jsonl, csv)
+// @Param        genre         query     string  false  "Exact genre filter"
+// @Param        year          query     string  false  "Release year (YYYY)"
+// @Param        updatedSince  query     string  false  "Only movies updated at or after this timestamp (YYYY-MM-DDTHH:MM:SS.sssZ)"
+// @Success      200           {array}   Movie   "One movie per line"
+// @Failure      400           {object}  Error   "Unknown format"
+// @Failure      401           {object}  Error   "Unauthorized"
+// @Failure      403           {object}  Error   "Forbidden (token lacks movies:read)"
+// @Router       /export/movies [get]
+func (h *Handler) exportMovies(ctx context.Context, c *app.RequestContext) {
+	f := MovieExportFilter{TenantID: currentTenant(c), Genre: c.Query("genre"), Year: c.Query("year"), UpdatedSince: c.Query("updatedSince")}
+	format := exportFormat(c)
+	streamExport(ctx, c, "movies", format, func(w io.Writer) (int, error) {
+		return ExportMovies(ctx, h.db, w, format, f)
+	})
+}
+
+// exportRatings godoc
+// @Summary      Export ratings
+// @Description  Streams every rating of the tenant, including reviews and moderation status, as JSON Lines (default) or CSV. The format comes from the format parameter or the Accept header.
+// @Tags         Export
+// @Produce      json
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        format        query     string  false  "Output format"  Enums(jsonl, csv)
+// @Param        title         query     string  false  "Movie title"
+// @Param        raterId       query     string  false  "Rater identifier"
+// @Param        updatedSince  query     string  false  "Only ratings updated at or after this timestamp (YYYY-MM-DDTHH:MM:SS.sssZ)"
+// @Success      200           {array}   RatingExport  "One rating per line"
+// @Failure      400           {object}  Error         "Unknown format"
+// @Failure      401           {object}  Error         "Unauthorized"
+// @Failure      403           {object}  Error         "Forbidden (token lacks ratings:admin)"
+// @Router       /export/ratings [get]
+func (h *Handler) exportRatings(ctx context.Context, c *app.RequestContext) {
+	f := RatingExportFilter{TenantID: currentTenant(c), Title: c.Query("title"), RaterID: c.Query("raterId"), UpdatedSince: c.Query("updatedSince")}
+	format := exportFormat(c)
+	streamExport(ctx, c, "ratings", format, func(w io.Writer) (int, error) {
+		return ExportRatings(ctx, h.db, w, format, f)
+	})
+}
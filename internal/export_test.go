@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// connProbe records whether the database was reachable every time the export wrote to it.
+type connProbe struct {
+	db  *sql.DB
+	out bytes.Buffer
+	err error
+}
+
+func (p *connProbe) Write(b []byte) (int, error) {
+	// The pool holds a single connection, so this blocks if the export still has rows open.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	var one int
+	if err := p.db.QueryRowContext(ctx, `SELECT 1`).Scan(&one); err != nil && p.err == nil {
+		p.err = err
+	}
+	return p.out.Write(b)
+}
+
+func TestExportWritesOutsideQueries(t *testing.T) {
+	ctx := context.Background()
+	db, err := initDB(ctx, "file:"+filepath.Join(t.TempDir(), "movies.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	const movies = 2*exportChunkSize + 1
+	err = WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		for i := range movies {
+			id := fmt.Sprintf("m%04d", i)
+			if _, err := tx.ExecContext(ctx, `INSERT INTO movies (id, title, release_date, genre) VALUES (?, ?, '2020-01-01', 'Drama')`, id, "Movie "+id); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO ratings (movie_id, rater_id, rating) VALUES (?, 'u1', 4)`, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name   string
+		format string
+		export func(*connProbe, string) (int, error)
+		header int
+	}{
+		{"movies as JSON Lines", ExportFormatJSONL, func(p *connProbe, f string) (int, error) {
+			return ExportMovies(ctx, db, p, f, MovieExportFilter{})
+		}, 0},
+		{"ratings as CSV", ExportFormatCSV, func(p *connProbe, f string) (int, error) {
+			return ExportRatings(ctx, db, p, f, RatingExportFilter{})
+		}, 1},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &connProbe{db: db}
+			n, err := tt.export(p, tt.format)
+			if err != nil || n != movies {
+				t.Fatalf("exported %d, %v; want %d", n, err, movies)
+			}
+			if p.err != nil {
+				t.Fatalf("connection busy during write: %v", p.err)
+			}
+			if lines := strings.Count(p.out.String(), "\n"); lines != movies+tt.header {
+				t.Fatalf("wrote %d lines, want %d", lines, movies+tt.header)
+			}
+		})
+	}
+}
@@ -17,7 +17,12 @@
 package internal
 
 import (
+	"Robin-Camp/internal/authz"
 	"Robin-Camp/internal/boxoffice"
+	"Robin-Camp/internal/jwt"
+	"Robin-Camp/internal/metrics"
+	"Robin-Camp/internal/moderation"
+	"Robin-Camp/internal/ratelimit"
 	"context"
 	"database/sql"
 	"errors"
@@ -27,6 +32,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/route"
@@ -38,7 +44,52 @@ type Handler struct {
 	boxClient BoxOfficeClient
 	authToken string
 
-	mu sync.RWMutex
+	reviewMaxLength int
+	contentFilter   moderation.Filter
+	reportThreshold int
+
+	raterLimiter        *ratelimit.Limiter
+	ipLimiter           *ratelimit.Limiter
+	registrationLimiter *ratelimit.Limiter
+	abuseWindow         time.Duration
+	abuseThreshold      int
+
+	raterAuthMode string
+	raterSecret   []byte
+	raterVerifier *jwt.Verifier
+
+	bearerVerifier   *jwt.Verifier
+	apiKeyAuth       bool
+	adminClientCerts bool
+
+	oauthSecret   []byte
+	oauthIssuer   string
+	oauthTTL      time.Duration
+	oauthVerifier *jwt.Verifier
+
+	anonymousRole    string
+	defaultRaterRole string
+
+	idempotencyTTL   time.Duration
+	idempotencyLease time.Duration
+	enrichmentWake   chan struct{}
+	maxBodySize      int
+
+	backupDir       string
+	backupRetention int
+
+	upstreamProber  UpstreamProber
+	healthCacheTTL  time.Duration
+	healthMu        sync.Mutex
+	healthReport    *HealthReport
+	healthCheckedAt time.Time
+	draining        func() bool
+
+	mu            sync.RWMutex
+	trendingCache map[string]trendingCacheEntry
+	tenantClients map[string]tenantBoxClient
+	// tenantBoxOptions configure clients built from tenant credentials.
+	tenantBoxOptions []boxoffice.Option
 	// pendingMovies  []*Movie
 	// pendingRatings []RatingResult
 }
@@ -48,8 +99,84 @@ type BoxOfficeClient interface {
 	GetMovieBoxOffice(ctx context.Context, title string) (*boxoffice.BoxOffice, error)
 }
 
-func NewHandler(db *sql.DB, boxClient BoxOfficeClient, authToken string) *Handler {
-	h := &Handler{db: db, boxClient: boxClient, authToken: authToken}
+// HandlerOption allows customizing the handler.
+type HandlerOption func(*Handler)
+
+// WithReviewMaxLength caps the length of review bodies in characters; non-positive values keep the default.
+func WithReviewMaxLength(n int) HandlerOption {
+	return func(h *Handler) {
+		if n > 0 {
+			h.reviewMaxLength = n
+		}
+	}
+}
+
+// WithContentFilter sets the filter used to hold reviews for moderation.
+func WithContentFilter(f moderation.Filter) HandlerOption {
+	return func(h *Handler) {
+		h.contentFilter = f
+	}
+}
+
+// WithReportThreshold sets how many distinct reports hide a rating pending review; non-positive values keep the default.
+func WithReportThreshold(n int) HandlerOption {
+	return func(h *Handler) {
+		if n > 0 {
+			h.reportThreshold = n
+		}
+	}
+}
+
+// WithRatingRateLimits sets the token buckets applied per X-Rater-Id and per client IP on rating submission.
+// A nil limiter disables that dimension.
+func WithRatingRateLimits(perRater, perIP *ratelimit.Limiter) HandlerOption {
+	return func(h *Handler) {
+		h.raterLimiter = perRater
+		h.ipLimiter = perIP
+	}
+}
+
+// WithRaterRegistrationLimit sets the token bucket applied per client IP on POST /raters; nil disables it.
+func WithRaterRegistrationLimit(perIP *ratelimit.Limiter) HandlerOption {
+	return func(h *Handler) {
+		h.registrationLimiter = perIP
+	}
+}
+
+// WithAbuseDetection configures how many first-time raters within window on one movie get flagged.
+func WithAbuseDetection(window time.Duration, threshold int) HandlerOption {
+	return func(h *Handler) {
+		if window > 0 {
+			h.abuseWindow = window
+		}
+		if threshold > 0 {
+			h.abuseThreshold = threshold
+		}
+	}
+}
+
+func NewHandler(db *sql.DB, boxClient BoxOfficeClient, authToken string, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		db:               db,
+		boxClient:        boxClient,
+		authToken:        authToken,
+		reviewMaxLength:  defaultReviewMaxLength,
+		reportThreshold:  defaultReportThreshold,
+		abuseWindow:      defaultAbuseWindow,
+		abuseThreshold:   defaultAbuseThreshold,
+		oauthTTL:         defaultOAuthTokenTTL,
+		anonymousRole:    authz.RoleViewer,
+		defaultRaterRole: authz.RoleRater,
+		idempotencyTTL:   defaultIdempotencyTTL,
+		idempotencyLease: defaultIdempotencyLease,
+		enrichmentWake:   make(chan struct{}, 1),
+		backupRetention:  DefaultBackupRetention,
+		healthCacheTTL:   defaultHealthCacheTTL,
+		maxBodySize:      defaultMaxBodySize,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
 	return h
 }
 
@@ -90,28 +217,66 @@ func NewHandler(db *sql.DB, boxClient BoxOfficeClient, authToken string) *Handle
 // 	})
 // }
 
-func upsertMovie(ctx context.Context, tx *sql.Tx, m *Movie) error {
-	_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO movies (id, title, release_date, genre, distributor, budget, mpa_rating, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')), (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')))`,
-		m.ID, m.Title, m.ReleaseDate, m.Genre, m.Distributor, m.Budget, m.MpaRating,
-	)
-	if err != nil {
-		return fmt.Errorf("insert movie: %w", err)
+// How upsertMovie treats a title the tenant already has.
+const (
+	// onConflictFail rejects the movie with errMovieExists.
+	onConflictFail = ""
+	// onConflictReturn leaves the stored movie untouched.
+	onConflictReturn = "return"
+	// onConflictUpdate overwrites the stored movie's fields, keeping its ID.
+	onConflictUpdate = "update"
+)
+
+// errMovieExists signals that the tenant already has a movie with the title.
+var errMovieExists = errors.New("movie already exists")
+
+// upsertMovie inserts m and reports whether it was created. When the title is taken, m.ID is set to the
+// stored movie's ID and onConflict decides whether to fail, keep the stored movie or update it.
+func upsertMovie(ctx context.Context, tx *sql.Tx, tenantID string, m *Movie, onConflict string) (bool, error) {
+	var existingID string
+	err := tx.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, tenantID, m.Title).Scan(&existingID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = tx.ExecContext(ctx, `INSERT INTO movies (id, tenant_id, title, release_date, genre, distributor, budget, mpa_rating, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')), (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')))`,
+			m.ID, tenantID, m.Title, m.ReleaseDate, m.Genre, m.Distributor, m.Budget, m.MpaRating, m.CreatedBy,
+		)
+		if err != nil {
+			return false, fmt.Errorf("insert movie: %w", err)
+		}
+	case err != nil:
+		return false, fmt.Errorf("lookup movie: %w", err)
+	default:
+		m.ID = existingID
+		switch onConflict {
+		case onConflictReturn:
+			return false, nil
+		case onConflictUpdate:
+			_, err = tx.ExecContext(ctx, `UPDATE movies SET release_date = ?, genre = ?, distributor = ?, budget = ?, mpa_rating = ?, updated_at = (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')) WHERE id = ?`,
+				m.ReleaseDate, m.Genre, m.Distributor, m.Budget, m.MpaRating, m.ID,
+			)
+			if err != nil {
+				return false, fmt.Errorf("update movie: %w", err)
+			}
+		default:
+			return false, errMovieExists
+		}
 	}
+	created := existingID == ""
 	if m.BoxOffice != nil {
 		var worldwidePtr *int64
 		if m.BoxOffice.Revenue.Worldwide != 0 {
 			v := m.BoxOffice.Revenue.Worldwide
 			worldwidePtr = &v
 		}
-		_, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO box_office (movie_id, currency, source, last_updated, revenue_worldwide, revenue_opening_weekend_usa) VALUES (?, ?, ?, ?, ?, ?)`,
-			m.ID, m.BoxOffice.Currency, m.BoxOffice.Source, m.BoxOffice.LastUpdated,
+		_, err = tx.ExecContext(ctx, `INSERT OR REPLACE INTO box_office (movie_id, tenant_id, currency, source, last_updated, revenue_worldwide, revenue_opening_weekend_usa) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			m.ID, tenantID, m.BoxOffice.Currency, m.BoxOffice.Source, m.BoxOffice.LastUpdated,
 			valueOrZero(worldwidePtr), valueOrZero(m.BoxOffice.Revenue.OpeningWeekendUsa),
 		)
 		if err != nil {
-			return fmt.Errorf("upsert box_office: %w", err)
+			return false, fmt.Errorf("upsert box_office: %w", err)
 		}
 	}
-	return nil
+	return created, nil
 }
 
 func valueOrZero(v *int64) int64 {
@@ -121,14 +286,49 @@ func valueOrZero(v *int64) int64 {
 	return *v
 }
 
-func upsertRating(ctx context.Context, tx *sql.Tx, r RatingResult) error {
-	_, err := tx.ExecContext(ctx, `INSERT INTO ratings (movie_id, rater_id, rating, updated_at) VALUES ((SELECT id FROM movies WHERE title = ?), ?, ?, (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now'))) ON CONFLICT(movie_id, rater_id) DO UPDATE SET rating = excluded.rating, updated_at = excluded.updated_at`,
-		r.MovieTitle, r.RaterID, r.Rating,
+// upsertRating stores the rating and appends a create or update event; it reports whether the rating is new.
+func upsertRating(ctx context.Context, tx *sql.Tx, tenantID string, r RatingResult, clientIP string) (bool, error) {
+	var movieID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, tenantID, r.MovieTitle).Scan(&movieID); err != nil {
+		return false, fmt.Errorf("lookup movie: %w", err)
+	}
+
+	var old sql.NullFloat64
+	err := tx.QueryRowContext(ctx, `SELECT rating FROM ratings WHERE movie_id = ? AND rater_id = ?`, movieID, r.RaterID).Scan(&old)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("lookup rating: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO ratings (movie_id, tenant_id, rater_id, rating, updated_at) VALUES (?, ?, ?, ?, (STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now'))) ON CONFLICT(movie_id, rater_id) DO UPDATE SET rating = excluded.rating, updated_at = excluded.updated_at`,
+		movieID, tenantID, r.RaterID, r.Rating,
 	)
 	if err != nil {
-		return fmt.Errorf("upsert rating: %w", err)
+		return false, fmt.Errorf("upsert rating: %w", err)
+	}
+
+	action := ratingEventCreate
+	var oldRating *float64
+	if old.Valid {
+		action = ratingEventUpdate
+		oldRating = &old.Float64
+	}
+	if r.Review != nil {
+		// An approval covers the text the admin read, so edited text drops back to visible and can be reported again.
+		title := nullIfEmpty(r.Review.Title)
+		_, err = tx.ExecContext(ctx, `UPDATE ratings SET review_title = ?, review_body = ?, review_spoiler = ?, review_language = ?, review_updated_at = updated_at,
+            moderation_status = CASE WHEN moderation_status = ? AND (review_title IS NOT ? OR review_body IS NOT ?) THEN ? ELSE moderation_status END
+            WHERE movie_id = ? AND rater_id = ?`,
+			title, r.Review.Body, r.Review.Spoiler, nullIfEmpty(r.Review.Language), moderationApproved, title, r.Review.Body, moderationVisible, movieID, r.RaterID,
+		)
+		if err != nil {
+			return false, fmt.Errorf("upsert review: %w", err)
+		}
 	}
-	return nil
+
+	if err := insertRatingEvent(ctx, tx, movieID, r.RaterID, action, oldRating, &r.Rating, clientIP); err != nil {
+		return false, err
+	}
+	return !old.Valid, nil
 }
 
 // listMovies godoc
@@ -142,7 +342,12 @@ func upsertRating(ctx context.Context, tx *sql.Tx, r RatingResult) error {
 // @Param        genre       query     string  false  "Exact genre filter"
 // @Param        limit       query     int     false  "Maximum number of items to return (default 20)"
 // @Param        cursor      query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Param        If-None-Match      header  string  false  "ETag from a previous response"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified from a previous response"
 // @Success      200         {object}  MoviePage
+// @Header       200         {string}  ETag           "Strong validator for the page"
+// @Header       200         {string}  Last-Modified  "Latest update among the movies on the page"
+// @Success      304         "Not modified"
 // @Failure      500         {object}  Error  "Internal server error"
 // @Router       /movies [get]
 func (h *Handler) listMovies(ctx context.Context, c *app.RequestContext) {
@@ -159,17 +364,21 @@ func (h *Handler) listMovies(ctx context.Context, c *app.RequestContext) {
 		}
 	}
 
-	movies, nextCursor, err := listMoviesFromDB(ctx, h.db, q, year, genre, limit, cursor)
+	movies, nextCursor, err := listMoviesFromDB(ctx, h.db, currentTenant(c), q, year, genre, limit, cursor)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
+		internalError(ctx, c, err)
 		return
 	}
-	c.JSON(http.StatusOK, MoviePage{Items: movies, NextCursor: nextCursor})
+	var lastModified string
+	for _, m := range movies {
+		lastModified = max(lastModified, m.UpdatedAt)
+	}
+	writeConditionalJSON(ctx, c, MoviePage{Items: movies, NextCursor: nextCursor}, lastModified)
 }
 
-func listMoviesFromDB(ctx context.Context, db *sql.DB, q, year, genre string, limit int, cursor string) ([]Movie, *string, error) {
-	var args []any
-	var where []string
+func listMoviesFromDB(ctx context.Context, db *sql.DB, tenantID, q, year, genre string, limit int, cursor string) ([]Movie, *string, error) {
+	args := []any{tenantID}
+	where := []string{"m.tenant_id = ?"}
 
 	if q != "" {
 		where = append(where, "title LIKE ?")
@@ -188,10 +397,7 @@ func listMoviesFromDB(ctx context.Context, db *sql.DB, q, year, genre string, li
 		args = append(args, cursor)
 	}
 
-	query := `SELECT m.id, m.title, m.release_date, m.genre, m.distributor, m.budget, m.mpa_rating, b.currency, b.source, b.last_updated, b.revenue_worldwide, b.revenue_opening_weekend_usa FROM movies m LEFT JOIN box_office b ON m.id = b.movie_id`
-	if len(where) > 0 {
-		query += " WHERE " + strings.Join(where, " AND ")
-	}
+	query := movieSelect + " WHERE " + strings.Join(where, " AND ")
 	query += " ORDER BY m.id LIMIT ?"
 	args = append(args, limit+1)
 
@@ -204,38 +410,12 @@ func listMoviesFromDB(ctx context.Context, db *sql.DB, q, year, genre string, li
 	var res []Movie
 	var lastID string
 	for rows.Next() {
-		var m Movie
-		var currency, source, lastUpdated sql.NullString
-		var revenueWorldwide, revenueOpeningWeekend sql.NullInt64
-
-		if err := rows.Scan(&m.ID, &m.Title, &m.ReleaseDate, &m.Genre, &m.Distributor, &m.Budget, &m.MpaRating, &currency, &source, &lastUpdated, &revenueWorldwide, &revenueOpeningWeekend); err != nil {
+		m, err := scanMovie(rows)
+		if err != nil {
 			return nil, nil, err
 		}
 		lastID = m.ID
-
-		// Populate BoxOffice if we have any box office data; otherwise leave as nil (serializes as null).
-		if currency.Valid || source.Valid || lastUpdated.Valid || revenueWorldwide.Valid || revenueOpeningWeekend.Valid {
-			bo := &BoxOffice{}
-			if revenueWorldwide.Valid {
-				bo.Revenue.Worldwide = revenueWorldwide.Int64
-			}
-			if revenueOpeningWeekend.Valid {
-				val := revenueOpeningWeekend.Int64
-				bo.Revenue.OpeningWeekendUsa = &val
-			}
-			if currency.Valid {
-				bo.Currency = currency.String
-			}
-			if source.Valid {
-				bo.Source = source.String
-			}
-			if lastUpdated.Valid {
-				bo.LastUpdated = lastUpdated.String
-			}
-			m.BoxOffice = bo
-		}
-
-		res = append(res, m)
+		res = append(res, *m)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, nil, err
@@ -249,62 +429,151 @@ func listMoviesFromDB(ctx context.Context, db *sql.DB, q, year, genre string, li
 	return res, nextCursor, nil
 }
 
+// movieSelect reads movies joined with their box office data in the column order scanMovie expects.
+const movieSelect = `SELECT m.id, m.title, m.release_date, m.genre, m.distributor, m.budget, m.mpa_rating, m.created_by, m.updated_at, b.currency, b.source, b.last_updated, b.revenue_worldwide, b.revenue_opening_weekend_usa FROM movies m LEFT JOIN box_office b ON m.id = b.movie_id`
+
+func scanMovie(row rowScanner) (*Movie, error) {
+	var m Movie
+	var currency, source, lastUpdated sql.NullString
+	var revenueWorldwide, revenueOpeningWeekend sql.NullInt64
+
+	if err := row.Scan(&m.ID, &m.Title, &m.ReleaseDate, &m.Genre, &m.Distributor, &m.Budget, &m.MpaRating, &m.CreatedBy, &m.UpdatedAt, &currency, &source, &lastUpdated, &revenueWorldwide, &revenueOpeningWeekend); err != nil {
+		return nil, err
+	}
+
+	// Populate BoxOffice if we have any box office data; otherwise leave as nil (serializes as null).
+	if currency.Valid || source.Valid || lastUpdated.Valid || revenueWorldwide.Valid || revenueOpeningWeekend.Valid {
+		bo := &BoxOffice{}
+		if revenueWorldwide.Valid {
+			bo.Revenue.Worldwide = revenueWorldwide.Int64
+		}
+		if revenueOpeningWeekend.Valid {
+			val := revenueOpeningWeekend.Int64
+			bo.Revenue.OpeningWeekendUsa = &val
+		}
+		if currency.Valid {
+			bo.Currency = currency.String
+		}
+		if source.Valid {
+			bo.Source = source.String
+		}
+		if lastUpdated.Valid {
+			bo.LastUpdated = lastUpdated.String
+		}
+		m.BoxOffice = bo
+	}
+	return &m, nil
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// getMovie loads the tenant's movie by title; it returns sql.ErrNoRows when there is none.
+func getMovie(ctx context.Context, q queryRower, tenantID, title string) (*Movie, error) {
+	return scanMovie(q.QueryRowContext(ctx, movieSelect+` WHERE m.tenant_id = ? AND m.title = ?`, tenantID, title))
+}
+
+// getMovieByTitle godoc
+// @Summary      Get a movie
+// @Description  Returns a single movie with its box office data.
+// @Tags         Movies
+// @Produce      json
+// @Param        title              path    string  true   "Movie title"
+// @Param        If-None-Match      header  string  false  "ETag from a previous response"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified from a previous response"
+// @Success      200    {object}  Movie
+// @Header       200    {string}  ETag           "Strong validator, usable with If-Match on POST /movies?onConflict=update"
+// @Header       200    {string}  Last-Modified  "Time of the last change to the movie"
+// @Success      304    "Not modified"
+// @Failure      404    {object}  Error  "Movie not found"
+// @Failure      500    {object}  Error  "Internal server error"
+// @Router       /movies/{title} [get]
+func (h *Handler) getMovieByTitle(ctx context.Context, c *app.RequestContext) {
+	movie, err := getMovie(ctx, h.db, currentTenant(c), strings.TrimSpace(c.Param("title")))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	writeConditionalJSON(ctx, c, movie, movie.UpdatedAt)
+}
+
 // createMovie godoc
 // @Summary      Create a new movie
-// @Description  Creates a new movie and synchronously enriches it with box office data when available.
+// @Description  Creates a new movie and synchronously enriches it with box office data when available. A title the tenant already has is rejected with 409 unless onConflict is set: "return" answers 200 with the stored movie, "update" overwrites its fields and answers 200.
 // @Tags         Movies
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
+// @Param        Idempotency-Key  header  string  false  "Client-generated key; retries with the same key and body replay the first response"
+// @Param        onConflict  query     string       false  "Behaviour when the title exists"  Enums(return, update)
+// @Param        If-Match    header    string       false  "ETag of the stored movie; the write fails with 412 when it has changed"
 // @Param        movie       body      MovieCreate  true   "Movie to create"
 // @Success      201         {object}  Movie
 // @Header       201         {string}  Location     "Location of the newly created movie resource"
+// @Success      200         {object}  Movie        "Existing movie returned or updated (onConflict)"
 // @Failure      400         {object}  Error        "Bad request"
 // @Failure      401         {object}  Error        "Unauthorized"
-// @Failure      422         {object}  Error        "Unprocessable entity (validation or invalid JSON)"
+// @Failure      403         {object}  Error        "Forbidden (token lacks movies:write)"
+// @Failure      409         {object}  Error        "Title already exists (details.id and Location identify the stored movie) or an Idempotency-Key request is still in progress"
+// @Failure      412         {object}  Error        "If-Match does not match the stored movie"
+// @Failure      413         {object}  Error        "Request body too large"
+// @Failure      422         {object}  Error        "Unprocessable entity (validation, invalid JSON, unknown field or Idempotency-Key reused with a different body)"
 // @Failure      500         {object}  Error        "Internal server error"
 // @Router       /movies [post]
 func (h *Handler) createMovie(ctx context.Context, c *app.RequestContext) {
 	var payload MovieCreate
-	if err := c.Bind(&payload); err != nil {
+	if err := bindJSON(c, &payload); err != nil {
 		// Invalid JSON/body format -> 422 Unprocessable Entity (per assignment tests)
-		c.JSON(http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "Invalid request body"})
+		invalidBody(c, http.StatusUnprocessableEntity, err)
 		return
 	}
-	// Validate required fields -> 422 Unprocessable Entity
-	if strings.TrimSpace(payload.Title) == "" || strings.TrimSpace(payload.Genre) == "" || strings.TrimSpace(payload.ReleaseDate) == "" {
-		c.JSON(http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "title, genre and releaseDate are required"})
+	// Validation failures are semantic errors -> 422 Unprocessable Entity
+	if err := validateMovieCreate(&payload); err != nil {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: err.Error()})
 		return
 	}
-	// Basic releaseDate format validation (YYYY-MM-DD) -> 422 on semantic error
-	if len(payload.ReleaseDate) != 10 || payload.ReleaseDate[4] != '-' || payload.ReleaseDate[7] != '-' {
-		c.JSON(http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "invalid releaseDate format"})
+	onConflict := c.Query("onConflict")
+	if onConflict != onConflictFail && onConflict != onConflictReturn && onConflict != onConflictUpdate {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "onConflict must be return or update"})
 		return
 	}
+	tenantID := currentTenant(c)
+
+	// Answer conflicts before the upstream lookup; upsertMovie re-checks inside the transaction.
+	if onConflict != onConflictUpdate {
+		existing, err := getMovie(ctx, h.db, tenantID, payload.Title)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			internalError(ctx, c, err)
+			return
+		}
+		if existing != nil {
+			movieConflict(c, existing, onConflict)
+			return
+		}
+	}
 
 	id := Now()
 	var box *BoxOffice
 
-	if h.boxClient != nil {
-		bo, err := h.boxClient.GetMovieBoxOffice(ctx, payload.Title)
+	client, err := h.boxClientFor(ctx, tenantID)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	if client != nil {
+		bo, err := client.GetMovieBoxOffice(ctx, payload.Title)
 		if err != nil && !errors.Is(err, boxoffice.ErrNotFound) {
 			// ignore upstream errors except 404
 			bo = nil
 		}
 		if bo != nil {
-			var worldwide int64
-			if bo.Revenue.Worldwide != nil {
-				worldwide = *bo.Revenue.Worldwide
-			}
-			box = &BoxOffice{
-				Revenue: Revenue{
-					Worldwide:         worldwide,
-					OpeningWeekendUsa: bo.Revenue.OpeningWeekendUSA,
-				},
-				Currency:    bo.Currency,
-				Source:      bo.Source,
-				LastUpdated: bo.LastUpdated,
-			}
+			box = toBoxOffice(bo)
 		}
 	}
 
@@ -318,104 +587,205 @@ func (h *Handler) createMovie(ctx context.Context, c *app.RequestContext) {
 		MpaRating:   payload.MpaRating,
 		BoxOffice:   box,
 	}
+	if p := currentPrincipal(c); p != nil {
+		movie.CreatedBy = nullIfEmpty(p.Subject)
+	}
 
 	// Write-through to DB so that subsequent GET /movies sees the new movie immediately.
+	var created bool
+	var stored *Movie
 	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
-		return upsertMovie(ctx, tx, movie)
+		if len(c.GetHeader("If-Match")) > 0 {
+			var current string
+			existing, err := getMovie(ctx, tx, tenantID, movie.Title)
+			switch {
+			case err == nil:
+				if current, err = movieETag(existing); err != nil {
+					return err
+				}
+			case !errors.Is(err, sql.ErrNoRows):
+				return err
+			}
+			if err := checkIfMatch(c, current); err != nil {
+				return err
+			}
+		}
+		var err error
+		if created, err = upsertMovie(ctx, tx, tenantID, movie, onConflict); err != nil || created {
+			return err
+		}
+		stored, err = getMovie(ctx, tx, tenantID, movie.Title)
+		return err
 	}); err != nil {
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
+		if errors.Is(err, errMovieExists) {
+			movieConflict(c, movie, onConflict)
+			return
+		}
+		if errors.Is(err, errPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		internalError(ctx, c, err)
 		return
 	}
 
 	c.Header("Location", "/movies/"+payload.Title)
+	if !created {
+		c.JSON(http.StatusOK, stored)
+		return
+	}
 	c.JSON(http.StatusCreated, movie)
 }
 
+// validateMovieCreate checks the required fields and the basic releaseDate format (YYYY-MM-DD).
+func validateMovieCreate(p *MovieCreate) error {
+	if strings.TrimSpace(p.Title) == "" || strings.TrimSpace(p.Genre) == "" || strings.TrimSpace(p.ReleaseDate) == "" {
+		return errors.New("title, genre and releaseDate are required")
+	}
+	if len(p.ReleaseDate) != 10 || p.ReleaseDate[4] != '-' || p.ReleaseDate[7] != '-' {
+		return errors.New("invalid releaseDate format")
+	}
+	return nil
+}
+
+// movieConflict answers a create for a title that already exists: 409 by default, the stored movie for onConflict=return.
+func movieConflict(c *app.RequestContext, existing *Movie, onConflict string) {
+	c.Header("Location", "/movies/"+existing.Title)
+	if onConflict == onConflictReturn {
+		c.JSON(http.StatusOK, existing)
+		return
+	}
+	writeError(c, http.StatusConflict, Error{Code: "CONFLICT", Message: "movie already exists", Details: map[string]string{"id": existing.ID}})
+}
+
 // submitRating godoc
 // @Summary      Submit or update a rating for a movie
-// @Description  Submits a rating for the given movie title. If the rater has already rated this movie, the rating is updated.
+// @Description  Submits a rating for the given movie title. If the rater has already rated this movie, the rating is updated. An attached review replaces the previous one; omitting it keeps the stored review.
 // @Tags         Ratings
 // @Accept       json
 // @Produce      json
 // @Security     RaterId
 // @Param        title       path      string        true   "Movie title"
-// @Param        X-Rater-Id  header    string        true   "Unique rater identifier"
-// @Param        rating      body      RatingSubmit  true   "Rating payload (0.5-5.0 in 0.5 steps)"
+// @Param        X-Rater-Id  header    string        true   "Rater identifier, signed rater token or rater JWT"
+// @Param        Idempotency-Key  header  string  false  "Client-generated key; retries with the same key and body replay the first response"
+// @Param        If-Match    header    string        false  "ETag of the caller's current rating; the update fails with 412 when it has changed"
+// @Param        rating      body      RatingSubmit  true   "Rating payload (0.5-5.0 in 0.5 steps) with an optional review"
 // @Success      201         {object}  RatingResult  "Rating created"
 // @Header       201         {string}  Location      "Location of the rating resource when created"
+// @Header       201         {string}  ETag          "Version of the stored rating"
 // @Success      200         {object}  RatingResult  "Rating updated"
-// @Failure      400         {object}  Error         "Bad request (invalid body or rating)"
+// @Header       200         {string}  ETag          "Version of the stored rating"
+// @Failure      400         {object}  Error         "Bad request (invalid body, unknown field or rating)"
 // @Failure      401         {object}  Error         "Unauthorized (missing or invalid X-Rater-Id)"
 // @Failure      404         {object}  Error         "Movie not found"
+// @Failure      409         {object}  Error         "A request with the same Idempotency-Key is still in progress"
+// @Failure      412         {object}  Error         "If-Match does not match the caller's current rating"
+// @Failure      413         {object}  Error         "Request body too large"
+// @Failure      422         {object}  Error         "Rating out of range, invalid review or Idempotency-Key reused with a different body"
+// @Failure      429         {object}  Error         "Rate limit exceeded for this rater or client IP"
 // @Failure      500         {object}  Error         "Internal server error"
 // @Router       /movies/{title}/ratings [post]
 func (h *Handler) submitRating(ctx context.Context, c *app.RequestContext) {
 	title := c.Param("title")
 	if len(title) == 0 {
-		c.JSON(http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "missing title"})
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "missing title"})
 		return
 	}
 
 	// Normalize title to avoid subtle mismatches and reuse consistently
 	normalizedTitle := strings.TrimSpace(title)
 
-	raterID := string(c.GetHeader("X-Rater-Id"))
+	raterID := currentRater(c)
 	if raterID == "" {
-		c.JSON(http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
+		writeError(c, http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
 		return
 	}
 
 	var payload RatingSubmit
-	if err := c.Bind(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "Invalid request body"})
+	if err := bindJSON(c, &payload); err != nil {
+		invalidBody(c, http.StatusBadRequest, err)
 		return
 	}
 	// Enforce allowed rating set: 0.5,1.0,...,5.0 (step 0.5) -> 422 on semantic validation failure
 	if payload.Rating < 0.5 || payload.Rating > 5.0 || math.Mod(payload.Rating*2, 1) != 0 {
-		c.JSON(http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "rating out of range"})
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "rating out of range"})
 		return
 	}
+	if payload.Review != nil {
+		if err := h.validateReview(payload.Review); err != nil {
+			writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: err.Error()})
+			return
+		}
+	}
+
+	moderationStatus, moderationReason := "", (*string)(nil)
+	if payload.Review != nil {
+		var err error
+		if moderationStatus, moderationReason, err = h.reviewVerdict(ctx, payload.Review); err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+	}
 
 	// Ensure movie exists and get its id so we can distinguish 404 movie-not-found separately.
 	var movieID string
-	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE title = ?`, normalizedTitle).Scan(&movieID); err != nil {
+	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, currentTenant(c), normalizedTitle).Scan(&movieID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			c.JSON(http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
+		internalError(ctx, c, err)
 		return
 	}
 
-	// Check if this rater already has a rating for the movie to decide between 201 (create) and 200 (update).
-	var existingCount int
-	if err := h.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM ratings WHERE movie_id = ? AND rater_id = ?`, movieID, raterID).Scan(&existingCount); err != nil {
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
-		return
-	}
-
-	statusCode := http.StatusCreated
-	if existingCount > 0 {
-		statusCode = http.StatusOK
-	}
-
-	// Directly persist rating so reads see it immediately.
+	// Directly persist rating so reads see it immediately; the upsert tells us whether to answer 201 (create) or 200 (update).
+	var created bool
+	var etag string
 	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
-		return upsertRating(ctx, tx, RatingResult{
+		current, err := currentRatingETag(ctx, tx, movieID, raterID)
+		if err != nil {
+			return err
+		}
+		if err := checkIfMatch(c, current); err != nil {
+			return err
+		}
+		created, err = upsertRating(ctx, tx, currentTenant(c), RatingResult{
 			MovieTitle: normalizedTitle,
 			RaterID:    raterID,
 			Rating:     payload.Rating,
-		})
+			Review:     payload.Review,
+		}, c.ClientIP())
+		if err != nil {
+			return err
+		}
+		if moderationStatus == moderationPending {
+			if err := holdFlaggedReview(ctx, tx, movieID, raterID, moderationReason); err != nil {
+				return err
+			}
+		}
+		etag, err = currentRatingETag(ctx, tx, movieID, raterID)
+		return err
 	}); err != nil {
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
+		if errors.Is(err, errPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		internalError(ctx, c, err)
 		return
 	}
+	c.Header("ETag", etag)
+
+	statusCode := http.StatusOK
+	if created {
+		statusCode = http.StatusCreated
+	}
 
 	// Prepare response
 	res := RatingResult{
 		MovieTitle: normalizedTitle,
 		RaterID:    raterID,
 		Rating:     payload.Rating,
+		Review:     payload.Review,
 	}
 
 	// Set Location header only when a new rating is created
@@ -428,98 +798,120 @@ func (h *Handler) submitRating(ctx context.Context, c *app.RequestContext) {
 
 // getRatingAggregate godoc
 // @Summary      Get rating aggregate for a movie
-// @Description  Returns the average rating (rounded to one decimal) and count of ratings for the given movie.
+// @Description  Returns the average rating (rounded to one decimal) and count of ratings for the given movie. Ratings hidden by moderation are excluded.
 // @Tags         Ratings
 // @Accept       json
 // @Produce      json
-// @Param        title   path      string  true  "Movie title"
-// @Success      200     {object}  RatingAggregate
-// @Failure      404     {object}  Error  "Movie not found or no ratings yet"
-// @Failure      500     {object}  Error  "Internal server error"
+// @Param        title        path      string  true   "Movie title"
+// @Param        trustedOnly        query   bool    false  "Ignore ratings from raters flagged by abuse detection"
+// @Param        If-None-Match      header  string  false  "ETag from a previous response"
+// @Param        If-Modified-Since  header  string  false  "Last-Modified from a previous response"
+// @Success      200          {object}  RatingAggregate
+// @Header       200          {string}  ETag           "Strong validator for the aggregate"
+// @Header       200          {string}  Last-Modified  "Time of the latest rating change for the movie"
+// @Success      304          "Not modified"
+// @Failure      404          {object}  Error  "Movie not found or no ratings yet"
+// @Failure      500          {object}  Error  "Internal server error"
 // @Router       /movies/{title}/rating [get]
 func (h *Handler) getRatingAggregate(ctx context.Context, c *app.RequestContext) {
 	title := c.Param("title")
 	if len(title) == 0 {
-		c.JSON(http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "missing title"})
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "missing title"})
 		return
 	}
 
 	var movieID string
-	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE title = ?`, string(title)).Scan(&movieID); err != nil {
+	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, currentTenant(c), string(title)).Scan(&movieID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			c.JSON(http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
+		internalError(ctx, c, err)
 		return
 	}
 
+	query := `SELECT AVG(rating), COUNT(*) FROM ratings WHERE movie_id = ? AND ` + visibleRatingClause
+	args := []any{movieID}
+	if c.Query("trustedOnly") == "true" {
+		query += ` AND rater_id NOT IN (SELECT rater_id FROM flagged_raters WHERE tenant_id = ?)`
+		args = append(args, currentTenant(c))
+	}
+
 	var avg sql.NullFloat64
 	var count sql.NullInt64
-	if err := h.db.QueryRowContext(ctx, `SELECT AVG(rating), COUNT(*) FROM ratings WHERE movie_id = ?`, movieID).Scan(&avg, &count); err != nil {
-		c.JSON(http.StatusInternalServerError, Error{Code: "INTERNAL", Message: err.Error()})
+	if err := h.db.QueryRowContext(ctx, query, args...).Scan(&avg, &count); err != nil {
+		internalError(ctx, c, err)
 		return
 	}
 	if !count.Valid || count.Int64 == 0 {
-		c.JSON(http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "no ratings"})
+		writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "no ratings"})
+		return
+	}
+
+	// Deletions leave no ratings row behind, so the event log also counts towards Last-Modified.
+	var lastModified sql.NullString
+	if err := h.db.QueryRowContext(ctx, `SELECT MAX(ts) FROM (SELECT MAX(MAX(updated_at), COALESCE(MAX(moderated_at), '')) AS ts FROM ratings WHERE movie_id = ? UNION ALL SELECT MAX(created_at) FROM rating_events WHERE movie_id = ?)`, movieID, movieID).Scan(&lastModified); err != nil {
+		internalError(ctx, c, err)
 		return
 	}
 
 	avgRounded := math.Round(avg.Float64*10) / 10
-	c.JSON(http.StatusOK, RatingAggregate{Average: avgRounded, Count: count.Int64})
+	writeConditionalJSON(ctx, c, RatingAggregate{Average: avgRounded, Count: count.Int64}, lastModified.String)
 }
 
-// requireBearer wraps handlers that need Bearer auth for writes.
-func (h *Handler) requireBearer(next app.HandlerFunc) app.HandlerFunc {
+// requireRater enforces X-Rater-Id header for rating endpoints and resolves it to a verified rater ID.
+func (h *Handler) requireRater(next app.HandlerFunc) app.HandlerFunc {
 	return func(ctx context.Context, c *app.RequestContext) {
-		raw := string(c.GetHeader("Authorization"))
-		if h.authToken == "" {
-			// If no auth token configured, treat as auth disabled (useful for local/dev),
-			// but the e2e tests require a token, so only skip strict checks when authToken is empty.
-			next(ctx, c)
-			return
-		}
-
-		if strings.TrimSpace(raw) == "" {
-			c.JSON(http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
+		raterID, err := h.resolveRater(ctx, string(c.GetHeader("X-Rater-Id")))
+		if err != nil && !errors.Is(err, errInvalidRaterToken) {
+			internalError(ctx, c, err)
 			return
 		}
-
-		const prefix = "Bearer "
-		if !strings.HasPrefix(raw, prefix) {
-			c.JSON(http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
-			return
-		}
-
-		token := strings.TrimSpace(strings.TrimPrefix(raw, prefix))
-		if token != h.authToken {
-			c.JSON(http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
+		if err != nil {
+			writeError(c, http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
 			return
 		}
-
+		c.Set(raterIDKey, raterID)
 		next(ctx, c)
 	}
 }
 
-// requireRater enforces X-Rater-Id header for rating endpoints.
-func (h *Handler) requireRater(next app.HandlerFunc) app.HandlerFunc {
+// metrics godoc
+// @Summary      Prometheus metrics
+// @Description  Request, box office, database and per-tenant catalogue metrics in the Prometheus text exposition format.
+// @Tags         System
+// @Produce      plain
+// @Security     BearerAuth
+// @Success      200  {string}  string  "Prometheus metrics"
+// @Failure      401  {object}  Error   "Unauthorized"
+// @Failure      403  {object}  Error   "Forbidden (token lacks metrics:read or is bound to a tenant)"
+// @Router       /metrics [get]
+func (h *Handler) metrics(ctx context.Context, c *app.RequestContext) {
+	metricsHandler(ctx, c)
+}
+
+var metricsHandler = metrics.Handler()
+
+// literalSuffix serves a custom-method route such as /movies:batch. The router reads the colon as the start
+// of a parameter called name, so the route would match any suffix; only the literal one reaches next.
+func literalSuffix(name, literal string, next app.HandlerFunc) app.HandlerFunc {
 	return func(ctx context.Context, c *app.RequestContext) {
-		raterID := string(c.GetHeader("X-Rater-Id"))
-		if raterID == "" {
-			c.JSON(http.StatusUnauthorized, Error{Code: "UNAUTHORIZED", Message: "Missing or invalid authentication信息"})
+		if c.Param(name) != literal {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "route not found"})
 			return
 		}
 		next(ctx, c)
 	}
 }
 
+// RegisterRoutes installs every route from routePolicies behind its declared authorization check.
 func (h *Handler) RegisterRoutes(rg *route.RouterGroup) {
-	movies := rg.Group("/movies")
-	{
-		movies.GET("", h.listMovies)
-		movies.POST("", h.requireBearer(h.createMovie))
-		movies.GET("/:title/rating", h.getRatingAggregate)
-		movies.POST("/:title/ratings", h.requireRater(h.submitRating))
+	for _, p := range h.routePolicies() {
+		handler := h.authorize(p)
+		if i := strings.LastIndexByte(p.Path, ':'); i > 0 && p.Path[i-1] != '/' {
+			handler = literalSuffix(p.Path[i+1:], p.Path[i:], handler)
+		}
+		rg.Handle(p.Method, p.Path, handler)
 	}
 
 	// healthz godoc
@@ -531,4 +923,7 @@ func (h *Handler) RegisterRoutes(rg *route.RouterGroup) {
 	rg.GET("/healthz", func(ctx context.Context, c *app.RequestContext) {
 		c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 	})
+
+	rg.GET("/livez", h.livez)
+	rg.GET("/readyz", h.readyz)
 }
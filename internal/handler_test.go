@@ -0,0 +1,140 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+const testAuthToken = "test-token"
+
+// newTestServer migrates a fresh database and serves a Handler that accepts authToken as the static
+// bearer token (none when empty) and is configured by opts.
+func newTestServer(t *testing.T, authToken string, opts ...HandlerOption) (*Handler, *route.Engine) {
+	t.Helper()
+	db, err := initDB(context.Background(), "file:"+filepath.Join(t.TempDir(), "movies.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	h := NewHandler(db, nil, authToken, opts...)
+	engine := route.NewEngine(config.NewOptions(nil))
+	h.RegisterRoutes(engine.Group("/"))
+	return h, engine
+}
+
+// do performs a request against engine. headers alternate names and values.
+func do(engine *route.Engine, method, url, body string, headers ...string) *ut.ResponseRecorder {
+	var hs []ut.Header
+	if body != "" {
+		hs = append(hs, ut.Header{Key: "Content-Type", Value: "application/json"})
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		hs = append(hs, ut.Header{Key: headers[i], Value: headers[i+1]})
+	}
+	return ut.PerformRequest(engine, method, url, &ut.Body{Body: strings.NewReader(body), Len: len(body)}, hs...)
+}
+
+func bearer(token string) []string {
+	return []string{"Authorization", "Bearer " + token}
+}
+
+// decode unmarshals the JSON response body into v, failing the test when it does not parse.
+func decode(t *testing.T, w *ut.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", w.Body.String(), err)
+	}
+}
+
+func expectStatus(t *testing.T, w *ut.ResponseRecorder, want int) {
+	t.Helper()
+	if got := w.Code; got != want {
+		t.Fatalf("status = %d, want %d; body: %s", got, want, w.Body.String())
+	}
+}
+
+// createTestMovie adds a movie to the tenant through the API.
+func createTestMovie(t *testing.T, engine *route.Engine, title, tenant string) {
+	t.Helper()
+	headers := bearer(testAuthToken)
+	if tenant != "" {
+		headers = append(headers, tenantHeader, tenant)
+	}
+	w := do(engine, "POST", "/movies", `{"title":"`+title+`","genre":"Drama","releaseDate":"2020-01-01"}`, headers...)
+	expectStatus(t, w, 201)
+}
+
+// createTestAPIKey issues an API key with scopes, optionally bound to tenant, and returns its plaintext.
+func createTestAPIKey(t *testing.T, engine *route.Engine, name, tenant string, scopes ...string) string {
+	t.Helper()
+	payload, _ := json.Marshal(APIKeyCreate{Name: name, Scopes: scopes, Tenant: tenant})
+	w := do(engine, "POST", "/admin/api-keys", string(payload), bearer(testAuthToken)...)
+	expectStatus(t, w, 201)
+	var k APIKeySecret
+	decode(t, w, &k)
+	return k.Key
+}
+
+func TestCreateMovieConflict(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	w := do(engine, "POST", "/movies", `{"title":"Heat","genre":"Crime","releaseDate":"1995-12-15"}`, bearer(testAuthToken)...)
+	expectStatus(t, w, http.StatusCreated)
+	var original Movie
+	decode(t, w, &original)
+
+	update := `{"title":"Heat","genre":"Thriller","releaseDate":"1995-12-15","distributor":"Warner Bros."}`
+	tests := []struct {
+		name      string
+		query     string
+		want      int
+		wantGenre string
+	}{
+		{"duplicate title is rejected", "", http.StatusConflict, ""},
+		{"return answers with the stored movie", "?onConflict=return", http.StatusOK, "Crime"},
+		{"update overwrites the stored movie", "?onConflict=update", http.StatusOK, "Thriller"},
+		{"unknown mode is rejected", "?onConflict=replace", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(engine, "POST", "/movies"+tt.query, update, bearer(testAuthToken)...)
+			expectStatus(t, w, tt.want)
+			switch tt.want {
+			case http.StatusConflict:
+				var e struct {
+					Code    string
+					Details map[string]string
+				}
+				decode(t, w, &e)
+				if e.Code != "CONFLICT" || e.Details["id"] != original.ID {
+					t.Fatalf("error = %+v, want CONFLICT naming %s", e, original.ID)
+				}
+			case http.StatusOK:
+				var m Movie
+				decode(t, w, &m)
+				if m.ID != original.ID || m.Genre != tt.wantGenre {
+					t.Fatalf("movie = %s %s, want %s %s", m.ID, m.Genre, original.ID, tt.wantGenre)
+				}
+			default:
+				return
+			}
+			if loc := w.Header().Get("Location"); loc != "/movies/Heat" {
+				t.Fatalf("Location = %q, want /movies/Heat", loc)
+			}
+		})
+	}
+
+	var stored Movie
+	decode(t, do(engine, "GET", "/movies/Heat", ""), &stored)
+	if stored.ID != original.ID || stored.Genre != "Thriller" || stored.Distributor == nil || *stored.Distributor != "Warner Bros." {
+		t.Fatalf("stored = %+v, want the update applied to %s", stored, original.ID)
+	}
+}
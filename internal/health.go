@@ -0,0 +1,243 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"Robin-Camp/internal/boxoffice"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// Health statuses. A degraded dependency leaves the service ready; only a down one fails /readyz.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+	// HealthDisabled marks an optional dependency that is not configured.
+	HealthDisabled = "disabled"
+)
+
+const (
+	defaultHealthCacheTTL = 5 * time.Second
+	// healthProbeTimeout bounds each dependency probe so a hung upstream cannot stall the probe endpoint.
+	healthProbeTimeout = 2 * time.Second
+	// enrichmentBacklogLimit is the number of pending lookups above which the queue reports degraded.
+	enrichmentBacklogLimit = 1000
+	// enrichmentStallAfter is how long a due job may wait before the worker is considered stuck.
+	enrichmentStallAfter = 10 * time.Minute
+)
+
+// UpstreamProber is implemented by box office clients that can report reachability and circuit state.
+type UpstreamProber interface {
+	Ping(ctx context.Context) error
+	CircuitState() string
+}
+
+// WithHealthChecks sets the upstream probed by /readyz (nil reports it as disabled) and how long
+// probe results are reused; non-positive values keep the default.
+func WithHealthChecks(upstream UpstreamProber, cacheTTL time.Duration) HandlerOption {
+	return func(h *Handler) {
+		h.upstreamProber = upstream
+		if cacheTTL > 0 {
+			h.healthCacheTTL = cacheTTL
+		}
+	}
+}
+
+// WithDrainCheck makes /readyz report down while draining returns true, so load balancers stop sending
+// traffic before the listener closes.
+func WithDrainCheck(draining func() bool) HandlerOption {
+	return func(h *Handler) {
+		h.draining = draining
+	}
+}
+
+// livez godoc
+// @Summary      Liveness probe
+// @Description  Reports that the process is serving requests. It checks no dependencies, so a failing database does not get the process restarted.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  HealthReport
+// @Router       /livez [get]
+func (h *Handler) livez(ctx context.Context, c *app.RequestContext) {
+	c.JSON(http.StatusOK, HealthReport{Status: HealthOK, CheckedAt: time.Now().UTC().Format(time.RFC3339)})
+}
+
+// readyz godoc
+// @Summary      Readiness probe
+// @Description  Reports each dependency: database ping and write probe, schema migration version, box office upstream reachability and the circuit state of the shared and per-tenant clients, and the enrichment queue backlog.
+// @Description  Results are cached for a few seconds. Upstream or queue problems report degraded and keep the service ready; a database or migration failure, or a shutdown in progress, reports down with 503.
+// @Tags         System
+// @Produce      json
+// @Success      200  {object}  HealthReport  "Ready (ok or degraded)"
+// @Failure      503  {object}  HealthReport  "Not ready"
+// @Router       /readyz [get]
+func (h *Handler) readyz(ctx context.Context, c *app.RequestContext) {
+	if h.draining != nil && h.draining() {
+		c.JSON(http.StatusServiceUnavailable, HealthReport{
+			Status:    HealthDown,
+			CheckedAt: time.Now().UTC().Format(time.RFC3339),
+			Checks:    map[string]HealthCheck{"shutdown": {Status: HealthDown, Error: "draining"}},
+		})
+		return
+	}
+	report := h.readiness(ctx)
+	status := http.StatusOK
+	if report.Status == HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// readiness returns the cached report while it is fresh; callers arriving during a refresh wait for it.
+func (h *Handler) readiness(ctx context.Context) HealthReport {
+	h.healthMu.Lock()
+	defer h.healthMu.Unlock()
+	if h.healthReport != nil && time.Since(h.healthCheckedAt) < h.healthCacheTTL {
+		return *h.healthReport
+	}
+
+	// Probes run detached from the request so that a client hanging up does not cache a failure.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), healthProbeTimeout)
+	defer cancel()
+
+	var upstream HealthCheck
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		upstream = h.checkUpstream(ctx)
+	}()
+	checks := map[string]HealthCheck{
+		"database":        h.checkDatabase(ctx),
+		"migrations":      h.checkMigrations(ctx),
+		"enrichmentQueue": h.checkEnrichmentQueue(ctx),
+	}
+	wg.Wait()
+	checks["boxOffice"] = upstream
+
+	report := HealthReport{Status: HealthOK, CheckedAt: time.Now().UTC().Format(time.RFC3339), Checks: checks}
+	for _, check := range checks {
+		switch {
+		case check.Status == HealthDown:
+			report.Status = HealthDown
+		case check.Status == HealthDegraded && report.Status == HealthOK:
+			report.Status = HealthDegraded
+		}
+	}
+	h.healthReport = &report
+	h.healthCheckedAt = time.Now()
+	return report
+}
+
+func (h *Handler) checkDatabase(ctx context.Context) HealthCheck {
+	start := time.Now()
+	if err := h.db.PingContext(ctx); err != nil {
+		hlog.CtxWarnf(ctx, "readiness: database ping: %v", err)
+		return HealthCheck{Status: HealthDown, Error: "ping failed"}
+	}
+	// A write catches a read-only or full disk, which a ping does not.
+	if _, err := h.db.ExecContext(ctx, `INSERT INTO health_probe (id, checked_at) VALUES (1, ?)
+        ON CONFLICT(id) DO UPDATE SET checked_at = excluded.checked_at`, Now()); err != nil {
+		hlog.CtxWarnf(ctx, "readiness: database write probe: %v", err)
+		return HealthCheck{Status: HealthDown, Error: "write probe failed"}
+	}
+	return HealthCheck{Status: HealthOK, LatencyMs: elapsedMs(start)}
+}
+
+func (h *Handler) checkMigrations(ctx context.Context) HealthCheck {
+	var version int
+	if err := h.db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+		hlog.CtxWarnf(ctx, "readiness: schema version: %v", err)
+		return HealthCheck{Status: HealthDown, Error: "schema version unreadable"}
+	}
+	check := HealthCheck{Status: HealthOK, Details: map[string]any{"version": version, "expected": SchemaVersion()}}
+	if version != SchemaVersion() {
+		check.Status = HealthDown
+		check.Error = "schema version mismatch"
+	}
+	return check
+}
+
+// checkUpstream probes the shared client and reports the circuits of clients built from tenant credentials;
+// any open circuit degrades the check.
+func (h *Handler) checkUpstream(ctx context.Context) HealthCheck {
+	tenants := h.tenantCircuits()
+	if h.upstreamProber == nil && len(tenants) == 0 {
+		return HealthCheck{Status: HealthDisabled}
+	}
+	check := HealthCheck{Status: HealthOK, Details: map[string]any{}}
+	if len(tenants) > 0 {
+		check.Details["tenantCircuits"] = tenants
+	}
+	if h.upstreamProber != nil {
+		circuit := h.upstreamProber.CircuitState()
+		check.Details["circuit"] = circuit
+		start := time.Now()
+		if err := h.upstreamProber.Ping(ctx); err != nil {
+			hlog.CtxWarnf(ctx, "readiness: box office upstream: %v", err)
+			check.Status = HealthDegraded
+			check.Error = "upstream unreachable"
+		} else {
+			check.LatencyMs = elapsedMs(start)
+			if circuit != boxoffice.CircuitClosed {
+				check.Status = HealthDegraded
+				check.Error = "circuit " + circuit
+			}
+		}
+	}
+	ids := make([]string, 0, len(tenants))
+	for id := range tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if circuit := tenants[id]; circuit != boxoffice.CircuitClosed {
+			check.Status = HealthDegraded
+			if check.Error == "" {
+				check.Error = "circuit " + circuit + " for tenant " + id
+			}
+		}
+	}
+	return check
+}
+
+func (h *Handler) checkEnrichmentQueue(ctx context.Context) HealthCheck {
+	var pending, failed int
+	var oldestDue sql.NullString
+	err := h.db.QueryRowContext(ctx, `SELECT
+            COUNT(*) FILTER (WHERE attempts < ?1),
+            COUNT(*) FILTER (WHERE attempts >= ?1),
+            MIN(CASE WHEN attempts < ?1 THEN next_attempt_at END)
+        FROM enrichment_jobs`, maxEnrichmentAttempts,
+	).Scan(&pending, &failed, &oldestDue)
+	if err != nil {
+		hlog.CtxWarnf(ctx, "readiness: enrichment queue: %v", err)
+		return HealthCheck{Status: HealthDegraded, Error: "queue unreadable"}
+	}
+
+	check := HealthCheck{Status: HealthOK, Details: map[string]any{"pending": pending, "failed": failed}}
+	if oldestDue.Valid {
+		check.Details["oldestDueAt"] = oldestDue.String
+	}
+	stalledBefore := time.Now().UTC().Add(-enrichmentStallAfter).Format(sqliteTimeLayout)
+	switch {
+	case pending > enrichmentBacklogLimit:
+		check.Status = HealthDegraded
+		check.Error = "backlog above limit"
+	case oldestDue.Valid && oldestDue.String < stalledBefore:
+		check.Status = HealthDegraded
+		check.Error = "worker behind schedule"
+	}
+	return check
+}
+
+func elapsedMs(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
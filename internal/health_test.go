@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestReadiness(t *testing.T) {
+	var draining bool
+	h, engine := newTestServer(t, testAuthToken, WithHealthChecks(nil, time.Nanosecond), WithDrainCheck(func() bool { return draining }))
+	readyz := func(t *testing.T, want int) HealthReport {
+		t.Helper()
+		w := do(engine, "GET", "/readyz", "")
+		expectStatus(t, w, want)
+		var report HealthReport
+		decode(t, w, &report)
+		return report
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		report := readyz(t, http.StatusOK)
+		want := map[string]string{"database": HealthOK, "migrations": HealthOK, "enrichmentQueue": HealthOK, "boxOffice": HealthDisabled}
+		for name, status := range want {
+			if got := report.Checks[name].Status; got != status {
+				t.Errorf("%s = %s, want %s", name, got, status)
+			}
+		}
+		if report.Status != HealthOK {
+			t.Errorf("status = %s, want ok", report.Status)
+		}
+	})
+
+	t.Run("schema version mismatch", func(t *testing.T) {
+		ctx := context.Background()
+		if _, err := h.db.ExecContext(ctx, `PRAGMA user_version = 1`); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { h.db.ExecContext(ctx, `PRAGMA user_version = `+strconv.Itoa(SchemaVersion())) })
+		report := readyz(t, http.StatusServiceUnavailable)
+		if report.Status != HealthDown || report.Checks["migrations"].Status != HealthDown {
+			t.Fatalf("report = %+v, want migrations down", report)
+		}
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		draining = true
+		t.Cleanup(func() { draining = false })
+		report := readyz(t, http.StatusServiceUnavailable)
+		if report.Checks["shutdown"].Status != HealthDown {
+			t.Fatalf("report = %+v, want shutdown down", report)
+		}
+	})
+
+	t.Run("liveness ignores dependencies", func(t *testing.T) {
+		draining = true
+		t.Cleanup(func() { draining = false })
+		expectStatus(t, do(engine, "GET", "/livez", ""), http.StatusOK)
+	})
+}
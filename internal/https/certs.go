@@ -0,0 +1,170 @@
+// Package https serves the API over TLS: certificates reloaded from disk, HTTP/2 negotiated through ALPN,
+// a plain HTTP listener that redirects to HTTPS, and client certificate checks for mutual TLS.
+package https
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/network"
+)
+
+// ALPN protocol identifiers.
+const (
+	protoHTTP2 = "h2"
+	protoHTTP1 = "http/1.1"
+)
+
+// Certificates holds the server key pair and, when mutual TLS is configured, the pool of CAs that client
+// certificates must chain to. Watch reloads them when the files change, so rotating a certificate does not
+// need a restart; connections already established keep the certificate they were opened with.
+type Certificates struct {
+	certFile, keyFile, clientCAFile string
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	stamps    []fileStamp
+}
+
+// fileStamp identifies a version of a file by size and modification time.
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// LoadCertificates reads the key pair and, if clientCAFile is not empty, the PEM bundle of client CAs.
+func LoadCertificates(certFile, keyFile, clientCAFile string) (*Certificates, error) {
+	c := &Certificates{certFile: certFile, keyFile: keyFile, clientCAFile: clientCAFile}
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// TLSConfig returns a server configuration that always presents the current certificate. It offers HTTP/2
+// before HTTP/1.1 when http2 is set, and asks for (but does not require) a client certificate when client
+// CAs are configured; routes that need one check it with ClientCertificate.
+func (c *Certificates) TLSConfig(http2 bool) *tls.Config {
+	protos := []string{protoHTTP1}
+	if http2 {
+		protos = []string{protoHTTP2, protoHTTP1}
+	}
+	base := &tls.Config{MinVersion: tls.VersionTLS12, NextProtos: protos}
+	base.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		cfg := &tls.Config{
+			MinVersion:   tls.VersionTLS12,
+			NextProtos:   protos,
+			Certificates: []tls.Certificate{*c.cert},
+		}
+		if c.clientCAs != nil {
+			cfg.ClientAuth = tls.VerifyClientCertIfGiven
+			cfg.ClientCAs = c.clientCAs
+		}
+		return cfg, nil
+	}
+	return base
+}
+
+// Watch checks the files every interval until ctx is cancelled and reloads them after any change.
+// A failed reload, such as a half-written key, is logged and the previous certificate stays in use.
+func (c *Certificates) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stamps, err := c.stat()
+		c.mu.RLock()
+		changed := err == nil && !sameStamps(stamps, c.stamps)
+		c.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := c.reload(); err != nil {
+			hlog.Warnf("https: reload certificates: %v", err)
+			continue
+		}
+		hlog.Infof("https: certificates reloaded from %s", c.certFile)
+	}
+}
+
+func (c *Certificates) reload() error {
+	stamps, err := c.stat()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+	var pool *x509.CertPool
+	if c.clientCAFile != "" {
+		pem, err := os.ReadFile(c.clientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CAs: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("client CA file contains no PEM certificates")
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cert, c.clientCAs, c.stamps = &cert, pool, stamps
+	return nil
+}
+
+func (c *Certificates) stat() ([]fileStamp, error) {
+	var stamps []fileStamp
+	for _, name := range []string{c.certFile, c.keyFile, c.clientCAFile} {
+		if name == "" {
+			continue
+		}
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		stamps = append(stamps, fileStamp{size: info.Size(), modTime: info.ModTime()})
+	}
+	return stamps, nil
+}
+
+func sameStamps(a, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// ClientCertificate returns the client certificate the request's connection presented and the server verified
+// against the client CAs, or nil for plain HTTP and for TLS connections without one.
+func ClientCertificate(c *app.RequestContext) *x509.Certificate {
+	conn, ok := c.GetConn().(network.ConnTLSer)
+	if !ok {
+		return nil
+	}
+	chains := conn.ConnectionState().VerifiedChains
+	if len(chains) == 0 || len(chains[0]) == 0 {
+		return nil
+	}
+	return chains[0][0]
+}
@@ -0,0 +1,253 @@
+package https
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/network"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"github.com/cloudwego/hertz/pkg/protocol/suite"
+	"golang.org/x/net/http2"
+)
+
+// HTTP2 serves connections that negotiated "h2" through ALPN. Register it with
+// h.AddProtocol(suite.HTTP2, ...) on a server started with TLS and ALPN. Framing is handled by
+// golang.org/x/net/http2; each request is copied into a Hertz RequestContext and passed to the
+// same middleware and routes as HTTP/1.1.
+type HTTP2 struct {
+	maxBodySize int
+	base        *http.Server
+	server      *http2.Server
+
+	mu      sync.Mutex
+	streams int
+	// drained is closed when the last stream finishes while a Shutdown is waiting.
+	drained chan struct{}
+}
+
+// NewHTTP2 returns an HTTP/2 protocol server factory. Request bodies above maxBodySize are answered
+// with 413. readTimeout and writeTimeout bound reading each request and writing its response, as they do
+// for HTTP/1.1; idle connections are closed after idleTimeout. A zero timeout disables it.
+func NewHTTP2(maxBodySize int, readTimeout, writeTimeout, idleTimeout time.Duration) *HTTP2 {
+	f := &HTTP2{
+		maxBodySize: maxBodySize,
+		base:        &http.Server{ReadTimeout: readTimeout, WriteTimeout: writeTimeout, IdleTimeout: idleTimeout},
+		server:      &http2.Server{IdleTimeout: idleTimeout},
+	}
+	// Links the two so that Shutdown sends GOAWAY on open connections.
+	http2.ConfigureServer(f.base, f.server)
+	return f
+}
+
+// New implements suite.ServerFactory.
+func (f *HTTP2) New(core suite.Core) (protocol.Server, error) {
+	return &http2Server{HTTP2: f, core: core}, nil
+}
+
+// Shutdown tells every open HTTP/2 connection to stop accepting new streams, then waits for the streams
+// in flight to finish or ctx to end, whichever comes first.
+func (f *HTTP2) Shutdown(ctx context.Context) error {
+	// base tracks no connections of its own, so this only sends GOAWAY and returns.
+	if err := f.base.Shutdown(ctx); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	if f.streams == 0 {
+		f.mu.Unlock()
+		return nil
+	}
+	if f.drained == nil {
+		f.drained = make(chan struct{})
+	}
+	drained := f.drained
+	f.mu.Unlock()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (f *HTTP2) streamStarted() {
+	f.mu.Lock()
+	f.streams++
+	f.mu.Unlock()
+}
+
+func (f *HTTP2) streamDone() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.streams--
+	if f.streams == 0 && f.drained != nil {
+		close(f.drained)
+		f.drained = nil
+	}
+}
+
+type http2Server struct {
+	*HTTP2
+	core suite.Core
+}
+
+func (s *http2Server) Serve(c context.Context, conn network.Conn) error {
+	// Hertz sets a read deadline for the TLS handshake; HTTP/2 manages its own from here on.
+	conn.SetReadDeadline(time.Time{})
+	s.server.ServeConn(conn, &http2.ServeConnOpts{
+		Context:    c,
+		BaseConfig: s.base,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s.streamStarted()
+			defer s.streamDone()
+			s.serveRequest(conn, w, r)
+		}),
+	})
+	return nil
+}
+
+// hopHeaders are connection-specific and must not be sent over HTTP/2.
+var hopHeaders = map[string]bool{
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+	"Content-Length":    true, // set by net/http from the body written
+}
+
+func (s *http2Server) serveRequest(conn network.Conn, w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(s.maxBodySize)+1))
+	if err != nil {
+		return // the client reset the stream
+	}
+	if len(body) > s.maxBodySize {
+		writeTooLarge(w, s.maxBodySize)
+		return
+	}
+
+	pool := s.core.GetCtxPool()
+	ctx := pool.Get().(*app.RequestContext)
+	defer func() {
+		ctx.Reset()
+		pool.Put(ctx)
+	}()
+	ctx.SetConn(conn)
+
+	req := &ctx.Request
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.RequestURI())
+	req.URI().SetScheme("https")
+	req.SetHost(r.Host)
+	req.Header.SetProtocol("HTTP/2.0")
+	for name, values := range r.Header {
+		if hopHeaders[name] {
+			continue
+		}
+		for i, v := range values {
+			// Set for the first value so Hertz parses special headers such as Content-Type and Cookie.
+			if i == 0 {
+				req.Header.Set(name, v)
+			} else {
+				req.Header.Add(name, v)
+			}
+		}
+	}
+	req.SetBody(body)
+	req.Header.SetContentLength(len(body))
+	resp := &ctx.Response
+	out := &responseWriter{w: w, resp: resp}
+	ctx.Set(responseWriterKey, out)
+
+	s.core.ServeHTTP(r.Context(), ctx)
+
+	if resp.GetHijackWriter() != nil {
+		// The handler streamed through HijackStream.
+		resp.GetHijackWriter().Finalize()
+		return
+	}
+	// Body() would drain a stream, so it is only consulted for buffered responses.
+	stream := resp.IsBodyStream()
+	if !stream && len(resp.Body()) > 0 {
+		w.Header().Set("Content-Length", strconv.Itoa(len(resp.Body())))
+	}
+	out.writeHeader()
+	if r.Method == http.MethodHead {
+		return
+	}
+	if stream {
+		io.Copy(out, resp.BodyStream())
+		return
+	}
+	w.Write(resp.Body())
+}
+
+// writeTooLarge answers an oversized body in the API's JSON error format. The request never reaches Hertz,
+// so the body is written here rather than by the routes' error helpers.
+func writeTooLarge(w http.ResponseWriter, limit int) {
+	body, _ := json.Marshal(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{"PAYLOAD_TOO_LARGE", fmt.Sprintf("request body exceeds the server limit of %d bytes", limit)})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	w.Write(body)
+}
+
+const responseWriterKey = "https.responseWriter"
+
+// HijackStream lets a handler stream its body over HTTP/2: after it returns true, writes to the
+// RequestContext go straight to the client and Flush sends them immediately. It returns false for
+// HTTP/1.1 connections, which stream with Hertz's chunked body writer instead.
+func HijackStream(c *app.RequestContext) bool {
+	out, ok := c.Value(responseWriterKey).(*responseWriter)
+	if ok {
+		c.Response.HijackWriter(out)
+	}
+	return ok
+}
+
+// responseWriter sends a Hertz response over an HTTP/2 stream. The status and headers are taken from
+// the Hertz response on the first write.
+type responseWriter struct {
+	w           http.ResponseWriter
+	resp        *protocol.Response
+	wroteHeader bool
+}
+
+func (o *responseWriter) writeHeader() {
+	if o.wroteHeader {
+		return
+	}
+	o.wroteHeader = true
+	header := o.w.Header()
+	o.resp.Header.VisitAll(func(k, v []byte) {
+		if name := http.CanonicalHeaderKey(string(k)); !hopHeaders[name] {
+			header.Add(name, string(v))
+		}
+	})
+	o.w.WriteHeader(o.resp.StatusCode())
+}
+
+func (o *responseWriter) Write(p []byte) (int, error) {
+	o.writeHeader()
+	return o.w.Write(p)
+}
+
+func (o *responseWriter) Flush() error {
+	o.writeHeader()
+	return http.NewResponseController(o.w).Flush()
+}
+
+func (o *responseWriter) Finalize() error {
+	o.writeHeader()
+	return nil
+}
@@ -0,0 +1,327 @@
+package https
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/protocol/suite"
+	"golang.org/x/net/http2"
+)
+
+const testMaxBodySize = 16
+
+func TestHTTP2(t *testing.T) {
+	pki := newTestPKI(t)
+	addr, release, _ := startHTTP2Server(t, pki)
+	client := pki.client(t, true)
+
+	get := func(t *testing.T, c *http.Client, method, path, body string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, "https://"+addr+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.ProtoMajor != 2 {
+			t.Fatalf("served over %s, want HTTP/2", resp.Proto)
+		}
+		return resp, string(b)
+	}
+
+	tests := []struct {
+		name         string
+		method, path string
+		body         string
+		anonymous    bool
+		wantStatus   int
+		wantBody     string
+	}{
+		{name: "GET", method: "GET", path: "/hello?name=h2", wantStatus: 200, wantBody: `{"hello":"h2","proto":"HTTP/2.0"}`},
+		{name: "HEAD has no body", method: "HEAD", path: "/hello?name=h2", wantStatus: 200},
+		{name: "body within the limit", method: "POST", path: "/echo", body: "0123456789", wantStatus: 200, wantBody: "0123456789"},
+		{name: "verified client certificate", method: "GET", path: "/whoami", wantStatus: 200, wantBody: "client-1"},
+		{name: "no client certificate", method: "GET", path: "/whoami", anonymous: true, wantStatus: 200, wantBody: "anonymous"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := client
+			if tt.anonymous {
+				c = pki.client(t, false)
+			}
+			resp, body := get(t, c, tt.method, tt.path, tt.body)
+			if resp.StatusCode != tt.wantStatus || body != tt.wantBody {
+				t.Fatalf("got %d %q, want %d %q", resp.StatusCode, body, tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+
+	t.Run("HEAD reports the GET length", func(t *testing.T) {
+		resp, _ := get(t, client, "HEAD", "/hello?name=h2", "")
+		if got := resp.Header.Get("Content-Length"); got != "33" {
+			t.Fatalf("Content-Length = %q, want 33", got)
+		}
+	})
+
+	t.Run("oversized body gets a JSON 413", func(t *testing.T) {
+		resp, body := get(t, client, "POST", "/echo", strings.Repeat("x", testMaxBodySize+1))
+		if resp.StatusCode != http.StatusRequestEntityTooLarge {
+			t.Fatalf("status = %d, want 413", resp.StatusCode)
+		}
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Fatalf("Content-Type = %q, want JSON", ct)
+		}
+		var e struct{ Code, Message string }
+		if err := json.Unmarshal([]byte(body), &e); err != nil || e.Code != "PAYLOAD_TOO_LARGE" || e.Message == "" {
+			t.Fatalf("body = %s, want a PAYLOAD_TOO_LARGE error", body)
+		}
+	})
+
+	t.Run("streamed response is flushed as it is written", func(t *testing.T) {
+		resp, err := client.Get("https://" + addr + "/stream")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		r := bufio.NewReader(resp.Body)
+		// The handler holds the second chunk back until the first has reached the client.
+		first, err := r.ReadString('\n')
+		if err != nil || first != "first\n" {
+			t.Fatalf("first chunk = %q, %v", first, err)
+		}
+		close(release)
+		rest, err := io.ReadAll(r)
+		if err != nil || string(rest) != "second\n" {
+			t.Fatalf("rest = %q, %v", rest, err)
+		}
+		if resp.ContentLength != -1 {
+			t.Fatalf("ContentLength = %d, want a streamed body", resp.ContentLength)
+		}
+	})
+}
+
+func TestHTTP2ShutdownWaitsForStreams(t *testing.T) {
+	pki := newTestPKI(t)
+	addr, release, h2 := startHTTP2Server(t, pki)
+
+	resp, err := pki.client(t, true).Get("https://" + addr + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r := bufio.NewReader(resp.Body)
+	if _, err := r.ReadString('\n'); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := h2.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Shutdown with a stream in flight = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		done <- h2.Shutdown(ctx)
+	}()
+	close(release)
+	if rest, err := io.ReadAll(r); err != nil || string(rest) != "second\n" {
+		t.Fatalf("rest = %q, %v; the stream should complete after GOAWAY", rest, err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Shutdown after the stream finished = %v", err)
+	}
+}
+
+// startHTTP2Server serves the test routes over TLS with HTTP/2 and returns the address, the channel that
+// releases the second chunk of /stream, and the HTTP/2 protocol server.
+func startHTTP2Server(t *testing.T, pki *testPKI) (string, chan struct{}, *HTTP2) {
+	t.Helper()
+	// Hertz listens by address, so a free port is found first.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	h := server.New(
+		server.WithHostPorts(addr),
+		server.WithTLS(pki.certs.TLSConfig(true)),
+		server.WithALPN(true),
+		server.WithDisablePrintRoute(true),
+		server.WithExitWaitTime(time.Second),
+	)
+	h2 := NewHTTP2(testMaxBodySize, 5*time.Second, 5*time.Second, time.Minute)
+	h.AddProtocol(suite.HTTP2, h2)
+
+	hello := func(ctx context.Context, c *app.RequestContext) {
+		c.JSON(http.StatusOK, map[string]string{"hello": c.Query("name"), "proto": string(c.Request.Header.GetProtocol())})
+	}
+	h.GET("/hello", hello)
+	h.HEAD("/hello", hello)
+	h.POST("/echo", func(ctx context.Context, c *app.RequestContext) {
+		c.Data(http.StatusOK, "text/plain", c.Request.Body())
+	})
+	h.GET("/whoami", func(ctx context.Context, c *app.RequestContext) {
+		name := "anonymous"
+		if cert := ClientCertificate(c); cert != nil {
+			name = cert.Subject.CommonName
+		}
+		c.String(http.StatusOK, name)
+	})
+	release := make(chan struct{})
+	h.GET("/stream", func(ctx context.Context, c *app.RequestContext) {
+		if !HijackStream(c) {
+			c.String(http.StatusInternalServerError, "not an HTTP/2 request")
+			return
+		}
+		c.SetContentType("text/plain")
+		c.Write([]byte("first\n"))
+		c.Flush()
+		select {
+		case <-release:
+			c.Write([]byte("second\n"))
+		case <-time.After(5 * time.Second):
+			c.Write([]byte("first chunk was not flushed\n"))
+		}
+		c.Flush()
+	})
+
+	go h.Spin()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		h2.Shutdown(ctx)
+		h.Shutdown(ctx)
+	})
+	for deadline := time.Now().Add(5 * time.Second); !h.IsRunning(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("server did not start")
+		}
+	}
+	return addr, release, h2
+}
+
+// testPKI is a CA with a server certificate for 127.0.0.1 and a client certificate, both written to disk.
+type testPKI struct {
+	pool       *x509.CertPool
+	certs      *Certificates
+	clientCert tls.Certificate
+}
+
+func newTestPKI(t *testing.T) *testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	caKey, caCert, caDER := issue(t, &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	serverKey, _, serverDER := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "server"},
+		IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, caKey)
+	clientKey, _, clientDER := issue(t, &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "client-1"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", caDER)
+	writePEM(t, filepath.Join(dir, "server.pem"), "CERTIFICATE", serverDER)
+	writeKey(t, filepath.Join(dir, "server-key.pem"), serverKey)
+	certs, err := LoadCertificates(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(caCert)
+	return &testPKI{
+		pool:       pool,
+		certs:      certs,
+		clientCert: tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey},
+	}
+}
+
+// client returns an HTTP/2-only client that trusts the test CA and presents the client certificate if withCert.
+func (p *testPKI) client(t *testing.T, withCert bool) *http.Client {
+	cfg := &tls.Config{RootCAs: p.pool}
+	if withCert {
+		cfg.Certificates = []tls.Certificate{p.clientCert}
+	}
+	tr := &http2.Transport{TLSClientConfig: cfg}
+	t.Cleanup(tr.CloseIdleConnections)
+	return &http.Client{Transport: tr, Timeout: 10 * time.Second}
+}
+
+var serial int64
+
+// issue creates a key and a certificate from tmpl signed by parent, or self-signed when parent is nil.
+func issue(t *testing.T, tmpl, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*ecdsa.PrivateKey, *x509.Certificate, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial++
+	tmpl.SerialNumber = big.NewInt(serial)
+	tmpl.NotBefore = time.Now().Add(-time.Hour)
+	tmpl.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, cert, der
+}
+
+func writePEM(t *testing.T, name, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(name, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeKey(t *testing.T, name string, key *ecdsa.PrivateKey) {
+	t.Helper()
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, name, "EC PRIVATE KEY", der)
+}
@@ -0,0 +1,33 @@
+package https
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/config"
+)
+
+// NewRedirectServer returns a plain HTTP server on addr that redirects every request to the same host and
+// path over HTTPS on httpsPort. GET and HEAD get 301; other methods get 308 so clients resend the body.
+func NewRedirectServer(addr string, httpsPort int, opts ...config.Option) *server.Hertz {
+	h := server.New(append(opts, server.WithHostPorts(addr), server.WithDisablePrintRoute(true))...)
+	h.NoRoute(func(ctx context.Context, c *app.RequestContext) {
+		host := string(c.Host())
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		}
+		if httpsPort != 443 {
+			host = net.JoinHostPort(host, strconv.Itoa(httpsPort))
+		}
+		status := http.StatusPermanentRedirect
+		if method := string(c.Method()); method == http.MethodGet || method == http.MethodHead {
+			status = http.StatusMovedPermanently
+		}
+		c.Redirect(status, []byte("https://"+host+string(c.Request.RequestURI())))
+	})
+	return h
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	idempotencyHeader         = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+
+	defaultIdempotencyTTL   = 24 * time.Hour
+	defaultIdempotencyLease = time.Minute
+)
+
+// WithIdempotencyTTL sets how long Idempotency-Key responses are kept for replay. Non-positive values keep the default.
+func WithIdempotencyTTL(ttl time.Duration) HandlerOption {
+	return func(h *Handler) {
+		if ttl > 0 {
+			h.idempotencyTTL = ttl
+		}
+	}
+}
+
+// WithIdempotencyLease sets how long a request that is still running holds its Idempotency-Key. A claim left
+// behind by a crashed process can be taken over by a retry once its lease runs out. Non-positive values keep
+// the default of one minute.
+func WithIdempotencyLease(lease time.Duration) HandlerOption {
+	return func(h *Handler) {
+		if lease > 0 {
+			h.idempotencyLease = lease
+		}
+	}
+}
+
+// idempotencyRecord is a stored Idempotency-Key entry. A zero StatusCode marks a request that is still running.
+type idempotencyRecord struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Location    string
+	Body        []byte
+}
+
+// idempotent replays the stored response when a request repeats an Idempotency-Key with the same body,
+// rejects reuse of a key with a different body and records the outcome of first attempts.
+// Keys are scoped to the tenant and the calling principal or rater.
+func (h *Handler) idempotent(next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		key := string(c.GetHeader(idempotencyHeader))
+		if key == "" {
+			next(ctx, c)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: fmt.Sprintf("%s must be at most %d characters", idempotencyHeader, maxIdempotencyKeyLength)})
+			return
+		}
+
+		scope := idempotencyScope(c)
+		hash := requestHash(c)
+		now := time.Now().UTC()
+
+		// Until the response is stored, expires_at holds the end of the lease and identifies this claim.
+		lease := now.Add(h.idempotencyLease).Format(sqliteTimeLayout)
+		rec, claimed, err := claimIdempotencyKey(ctx, h.db, scope, key, hash, now, lease)
+		if err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		if !claimed {
+			switch {
+			case rec.RequestHash != hash:
+				writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: idempotencyHeader + " was already used with a different request"})
+			case rec.StatusCode == 0:
+				writeError(c, http.StatusConflict, Error{Code: "CONFLICT", Message: "a request with this " + idempotencyHeader + " is still in progress"})
+			default:
+				if rec.Location != "" {
+					c.Header("Location", rec.Location)
+				}
+				c.Header(idempotencyReplayedHeader, "true")
+				c.Data(rec.StatusCode, rec.ContentType, rec.Body)
+			}
+			return
+		}
+
+		completed := false
+		defer func() {
+			// Release the claim when the request failed or panicked so that a retry runs again.
+			if !completed {
+				_, _ = h.db.ExecContext(context.WithoutCancel(ctx), `DELETE FROM idempotency_keys WHERE scope = ? AND key = ? AND status_code = 0 AND expires_at = ?`, scope, key, lease)
+			}
+		}()
+
+		next(ctx, c)
+
+		status := c.Response.StatusCode()
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			return
+		}
+		// A request that outlived its lease may have lost the key to a retry; the retry's outcome is kept then.
+		if _, err := h.db.ExecContext(ctx, `UPDATE idempotency_keys SET status_code = ?, content_type = ?, location = ?, body = ?, expires_at = ? WHERE scope = ? AND key = ? AND status_code = 0 AND expires_at = ?`,
+			status, string(c.Response.Header.ContentType()), nullIfEmpty(string(c.Response.Header.Peek("Location"))), c.Response.Body(),
+			time.Now().UTC().Add(h.idempotencyTTL).Format(sqliteTimeLayout), scope, key, lease,
+		); err != nil {
+			return
+		}
+		completed = true
+	}
+}
+
+// claimIdempotencyKey inserts an in-progress entry for the key that expires at lease, or returns the live entry
+// already stored for it. Expired entries, including in-progress ones whose lease ran out, are pruned first.
+func claimIdempotencyKey(ctx context.Context, db *sql.DB, scope, key, hash string, now time.Time, lease string) (*idempotencyRecord, bool, error) {
+	var rec idempotencyRecord
+	claimed := false
+	err := WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at < ?`, now.Format(sqliteTimeLayout)); err != nil {
+			return fmt.Errorf("prune idempotency keys: %w", err)
+		}
+
+		var contentType, location sql.NullString
+		err := tx.QueryRowContext(ctx, `SELECT request_hash, status_code, content_type, location, body FROM idempotency_keys WHERE scope = ? AND key = ?`, scope, key).
+			Scan(&rec.RequestHash, &rec.StatusCode, &contentType, &location, &rec.Body)
+		if err == nil {
+			rec.ContentType = contentType.String
+			rec.Location = location.String
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("lookup idempotency key: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `INSERT INTO idempotency_keys (scope, key, request_hash, expires_at) VALUES (?, ?, ?, ?)`,
+			scope, key, hash, lease,
+		); err != nil {
+			return fmt.Errorf("store idempotency key: %w", err)
+		}
+		claimed = true
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &rec, claimed, nil
+}
+
+// idempotencyScope namespaces keys per tenant and caller so that clients cannot replay each other's responses.
+func idempotencyScope(c *app.RequestContext) string {
+	caller := subjectRater + ":" + currentRater(c)
+	if p := currentPrincipal(c); p != nil {
+		caller = subjectPrincipal + ":" + p.Subject
+	}
+	return currentTenant(c) + "|" + caller
+}
+
+// requestHash fingerprints the method, path, query string and body of the request.
+func requestHash(c *app.RequestContext) string {
+	sum := sha256.New()
+	sum.Write(c.Method())
+	sum.Write([]byte{0})
+	sum.Write(c.Request.URI().RequestURI())
+	sum.Write([]byte{0})
+	sum.Write(c.Request.Body())
+	return hex.EncodeToString(sum.Sum(nil))
+}
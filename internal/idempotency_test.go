@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+func TestIdempotentRetries(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	const body = `{"title":"Heat","genre":"Drama","releaseDate":"1995-12-15"}`
+	post := func(url, key, body string) (int, string) {
+		w := do(engine, "POST", url, body, append(bearer(testAuthToken), idempotencyHeader, key)...)
+		return w.Code, string(w.Header().Peek(idempotencyReplayedHeader))
+	}
+
+	tests := []struct {
+		name         string
+		url, key     string
+		body         string
+		want         int
+		wantReplayed string
+	}{
+		{"first attempt runs", "/movies", "k1", body, http.StatusCreated, ""},
+		{"retry replays the response", "/movies", "k1", body, http.StatusCreated, "true"},
+		{"different body is rejected", "/movies", "k1", `{"title":"Ran","genre":"Drama","releaseDate":"1985-06-01"}`, http.StatusUnprocessableEntity, ""},
+		{"different query string is rejected", "/movies?onConflict=update", "k1", body, http.StatusUnprocessableEntity, ""},
+		{"new key executes against the existing movie", "/movies", "k2", body, http.StatusConflict, ""},
+		{"stored conflict is replayed", "/movies", "k2", body, http.StatusConflict, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, replayed := post(tt.url, tt.key, tt.body)
+			if status != tt.want || replayed != tt.wantReplayed {
+				t.Fatalf("got (%d, %q), want (%d, %q)", status, replayed, tt.want, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestIdempotencyKeysAreScopedToCaller(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	createTestMovie(t, engine, "Heat", "")
+
+	first := do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "u1", idempotencyHeader, "same")
+	expectStatus(t, first, http.StatusCreated)
+	// Another rater reusing the key gets a fresh execution rather than u1's stored response.
+	second := do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "u2", idempotencyHeader, "same")
+	expectStatus(t, second, http.StatusCreated)
+	if v := second.Header().Peek(idempotencyReplayedHeader); len(v) != 0 {
+		t.Fatal("response for u2 was replayed from u1")
+	}
+}
+
+func TestIdempotencyLease(t *testing.T) {
+	h, engine := newTestServer(t, testAuthToken)
+	const body = `{"title":"Heat","genre":"Drama","releaseDate":"1995-12-15"}`
+	post := func(key string) *ut.ResponseRecorder {
+		return do(engine, "POST", "/movies", body, append(bearer(testAuthToken), idempotencyHeader, key)...)
+	}
+	// markInProgress turns the stored response for key back into a claim whose lease ends at leaseEnd,
+	// as if the request that made it were still running or had crashed.
+	markInProgress := func(key string, leaseEnd time.Time) {
+		t.Helper()
+		if _, err := h.db.ExecContext(context.Background(), `UPDATE idempotency_keys SET status_code = 0, body = NULL, expires_at = ? WHERE key = ?`,
+			leaseEnd.UTC().Format(sqliteTimeLayout), key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expectStatus(t, post("k1"), http.StatusCreated)
+	markInProgress("k1", time.Now().Add(time.Minute))
+	expectStatus(t, post("k1"), http.StatusConflict)
+
+	// Once the lease has run out the retry executes again; the movie now exists, so it conflicts.
+	markInProgress("k1", time.Now().Add(-time.Second))
+	w := post("k1")
+	expectStatus(t, w, http.StatusConflict)
+	if v := w.Header().Peek(idempotencyReplayedHeader); len(v) != 0 {
+		t.Fatal("expired claim was replayed instead of taken over")
+	}
+
+	var status int
+	var expires string
+	if err := h.db.QueryRow(`SELECT status_code, expires_at FROM idempotency_keys WHERE key = 'k1'`).Scan(&status, &expires); err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusConflict {
+		t.Fatalf("stored status = %d, want 409", status)
+	}
+	if ts, err := time.Parse(sqliteTimeLayout, expires); err != nil || time.Until(ts) < time.Hour {
+		t.Fatalf("completed entry expires at %s, want the full TTL", expires)
+	}
+}
@@ -0,0 +1,352 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Input formats accepted by ImportMovies.
+const (
+	ImportFormatJSONL = "jsonl"
+	ImportFormatCSV   = "csv"
+)
+
+// Row outcomes reported by ImportMovies.
+const (
+	ImportCreated = "created"
+	ImportUpdated = "updated"
+	ImportSkipped = "skipped"
+	ImportFailed  = "failed"
+)
+
+const (
+	// importChunkSize is the number of rows written per transaction.
+	importChunkSize = 200
+	// maxImportLineBytes caps a single JSON Lines record.
+	maxImportLineBytes = 1 << 20
+)
+
+// ErrImportFormat is returned for an unknown format or a CSV header without the required columns.
+var ErrImportFormat = errors.New("invalid import format")
+
+// ImportOptions controls ImportMovies.
+type ImportOptions struct {
+	TenantID string
+	Format   string
+	// Update overwrites movies whose title already exists instead of skipping them.
+	Update bool
+	// CreatedBy is recorded as created_by on new movies.
+	CreatedBy string
+}
+
+// ImportMovies reads MovieCreate records from r and inserts them in transactions of importChunkSize rows.
+// Rows that cannot be parsed or fail validation are reported as failed without aborting the import; new movies
+// are queued for box office enrichment. On a database error the report covers the rows committed so far.
+func ImportMovies(ctx context.Context, db *sql.DB, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	if opts.TenantID == "" {
+		opts.TenantID = DefaultTenant
+	}
+	var records movieRecordReader
+	switch opts.Format {
+	case ImportFormatJSONL, "":
+		records = newJSONLRecords(r)
+	case ImportFormatCSV:
+		var err error
+		if records, err = newCSVRecords(r); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrImportFormat, opts.Format)
+	}
+
+	report := &ImportReport{Rows: []ImportRow{}}
+	chunk := make([]pendingImportRow, 0, importChunkSize)
+	for {
+		line, rec, err := records.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *importRowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return report, err
+		}
+
+		row := pendingImportRow{record: rec, ImportRow: ImportRow{Line: line, Title: rec.Title}}
+		switch {
+		case rowErr != nil:
+			row.fail(rowErr.Error())
+		default:
+			if err := validateMovieCreate(&rec); err != nil {
+				row.fail(err.Error())
+			}
+		}
+		chunk = append(chunk, row)
+
+		if len(chunk) == importChunkSize {
+			if err := writeImportChunk(ctx, db, opts, chunk, report); err != nil {
+				return report, err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := writeImportChunk(ctx, db, opts, chunk, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+type pendingImportRow struct {
+	ImportRow
+	record MovieCreate
+}
+
+func (r *pendingImportRow) fail(reason string) {
+	r.Status = ImportFailed
+	r.Reason = reason
+}
+
+// writeImportChunk stores the valid rows of chunk in one transaction and appends every row's outcome to report.
+func writeImportChunk(ctx context.Context, db *sql.DB, opts ImportOptions, chunk []pendingImportRow, report *ImportReport) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+	onConflict := onConflictReturn
+	if opts.Update {
+		onConflict = onConflictUpdate
+	}
+
+	var lastID string
+	err := WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		for i := range chunk {
+			row := &chunk[i]
+			if row.Status == ImportFailed {
+				continue
+			}
+			// Now has nanosecond resolution, but a tight loop can still observe the same value twice.
+			id := Now()
+			for id == lastID {
+				id = Now()
+			}
+			lastID = id
+
+			p := row.record
+			m := &Movie{ID: id, Title: p.Title, ReleaseDate: p.ReleaseDate, Genre: p.Genre, Distributor: p.Distributor, Budget: p.Budget, MpaRating: p.MpaRating, CreatedBy: nullIfEmpty(opts.CreatedBy)}
+			created, err := upsertMovie(ctx, tx, opts.TenantID, m, onConflict)
+			if err != nil {
+				return err
+			}
+			row.ID = &m.ID
+			switch {
+			case created:
+				row.Status = ImportCreated
+				if err := enqueueEnrichment(ctx, tx, m.ID, opts.TenantID, m.Title); err != nil {
+					return err
+				}
+			case opts.Update:
+				row.Status = ImportUpdated
+			default:
+				row.Status = ImportSkipped
+				row.Reason = "title already exists"
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("import rows %d-%d: %w", chunk[0].Line, chunk[len(chunk)-1].Line, err)
+	}
+
+	for _, row := range chunk {
+		switch row.Status {
+		case ImportCreated:
+			report.Created++
+		case ImportUpdated:
+			report.Updated++
+		case ImportSkipped:
+			report.Skipped++
+		case ImportFailed:
+			report.Failed++
+		}
+		report.Rows = append(report.Rows, row.ImportRow)
+	}
+	return nil
+}
+
+// movieRecordReader yields MovieCreate records with their line numbers. io.EOF ends the stream and an
+// *importRowError reports a record that could not be parsed; any other error aborts the import.
+type movieRecordReader interface {
+	Next() (int, MovieCreate, error)
+}
+
+type importRowError struct {
+	reason string
+}
+
+func (e *importRowError) Error() string { return e.reason }
+
+type jsonlRecords struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLRecords(r io.Reader) *jsonlRecords {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineBytes)
+	return &jsonlRecords{scanner: scanner}
+}
+
+func (j *jsonlRecords) Next() (int, MovieCreate, error) {
+	for j.scanner.Scan() {
+		j.line++
+		raw := bytes.TrimSpace(j.scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		var rec MovieCreate
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return j.line, rec, &importRowError{reason: "invalid JSON: " + err.Error()}
+		}
+		return j.line, rec, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return j.line + 1, MovieCreate{}, fmt.Errorf("read line %d: %w", j.line+1, err)
+	}
+	return j.line, MovieCreate{}, io.EOF
+}
+
+type csvRecords struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// newCSVRecords reads the header row; columns are matched case-insensitively against the MovieCreate JSON names.
+func newCSVRecords(r io.Reader) (*csvRecords, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: empty CSV", ErrImportFormat)
+		}
+		return nil, fmt.Errorf("%w: read CSV header: %v", ErrImportFormat, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "genre", "releasedate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: CSV header must include title, genre and releaseDate", ErrImportFormat)
+		}
+	}
+	return &csvRecords{reader: reader, columns: columns}, nil
+}
+
+func (c *csvRecords) Next() (int, MovieCreate, error) {
+	fields, err := c.reader.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, MovieCreate{}, &importRowError{reason: "invalid CSV: " + parseErr.Err.Error()}
+		}
+		return 0, MovieCreate{}, err
+	}
+	line, _ := c.reader.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(fields) {
+			return strings.TrimSpace(fields[i])
+		}
+		return ""
+	}
+	optional := func(name string) *string {
+		return nullIfEmpty(field(name))
+	}
+
+	rec := MovieCreate{
+		Title:       field("title"),
+		Genre:       field("genre"),
+		ReleaseDate: field("releasedate"),
+		Distributor: optional("distributor"),
+		MpaRating:   optional("mparating"),
+	}
+	if v := field("budget"); v != "" {
+		budget, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return line, rec, &importRowError{reason: "invalid budget"}
+		}
+		rec.Budget = &budget
+	}
+	return line, rec, nil
+}
+
+// importMovies godoc
+// @Summary      Bulk import movies
+// @Description  Streams MovieCreate records as JSON Lines (default) or CSV with a header row, validates each row and inserts them in chunked transactions. Existing titles are skipped unless onConflict=update. Box office enrichment is queued instead of running inline. The response reports the outcome of every row; invalid rows do not abort the import.
+// @Tags         Movies
+// @Accept       plain
+// @Produce      json
+// @Security     BearerAuth
+// @Param        format      query     string  false  "Input format; defaults from Content-Type (text/csv selects CSV)"  Enums(jsonl, csv)
+// @Param        onConflict  query     string  false  "Behaviour for titles that already exist (default skip)"  Enums(skip, update)
+// @Param        records     body      string  true   "JSON Lines or CSV records"
+// @Success      200         {object}  ImportReport
+// @Failure      400         {object}  Error  "Bad request (unknown format or onConflict)"
+// @Failure      401         {object}  Error  "Unauthorized"
+// @Failure      403         {object}  Error  "Forbidden (token lacks movies:write)"
+// @Failure      422         {object}  Error  "CSV header lacks required columns or a JSON Lines record is too long"
+// @Failure      500         {object}  Error  "Internal server error"
+// @Router       /movies:batch [post]
+func (h *Handler) importMovies(ctx context.Context, c *app.RequestContext) {
+	format := c.Query("format")
+	if format == "" {
+		format = ImportFormatJSONL
+		if strings.HasPrefix(string(c.ContentType()), "text/csv") {
+			format = ImportFormatCSV
+		}
+	}
+	if format != ImportFormatJSONL && format != ImportFormatCSV {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "format must be jsonl or csv"})
+		return
+	}
+	onConflict := c.Query("onConflict")
+	if onConflict != "" && onConflict != "skip" && onConflict != onConflictUpdate {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "onConflict must be skip or update"})
+		return
+	}
+
+	opts := ImportOptions{TenantID: currentTenant(c), Format: format, Update: onConflict == onConflictUpdate}
+	if p := currentPrincipal(c); p != nil {
+		opts.CreatedBy = p.Subject
+	}
+
+	var body io.Reader = bytes.NewReader(c.Request.Body())
+	if c.Request.IsBodyStream() {
+		body = c.Request.BodyStream()
+	}
+	report, err := ImportMovies(ctx, h.db, body, opts)
+	if report != nil && report.Created > 0 {
+		h.wakeEnrichment()
+	}
+	if err != nil {
+		if errors.Is(err, ErrImportFormat) || errors.Is(err, bufio.ErrTooLong) {
+			writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: err.Error()})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestImportReportsEveryRow(t *testing.T) {
+	h, engine := newTestServer(t, testAuthToken)
+	createTestMovie(t, engine, "Heat", "")
+
+	type row struct {
+		line   int
+		title  string
+		status string
+		reason string
+	}
+	tests := []struct {
+		name   string
+		query  string
+		body   string
+		counts [4]int // created, updated, skipped, failed
+		rows   []row
+	}{
+		{
+			name: "JSON Lines",
+			body: `{"title":"Alien","genre":"Horror","releaseDate":"1979-05-25"}
+
+{"title":"Ran","genre":
+{"title":"Up","releaseDate":"2009-05-29"}
+{"title":"Heat","genre":"Crime","releaseDate":"1995-12-15"}
+{"title":"Alien","genre":"Horror","releaseDate":"1979-05-25"}
+`,
+			counts: [4]int{1, 0, 2, 2},
+			rows: []row{
+				{1, "Alien", ImportCreated, ""},
+				{3, "", ImportFailed, "invalid JSON"},
+				{4, "Up", ImportFailed, "title, genre and releaseDate are required"},
+				{5, "Heat", ImportSkipped, "title already exists"},
+				{6, "Alien", ImportSkipped, "title already exists"},
+			},
+		},
+		{
+			name:  "CSV with updates",
+			query: "?format=csv&onConflict=update",
+			body: `title,genre,releaseDate,budget
+Heat,Thriller,1995-12-15,60000000
+Brazil,Comedy,1985-02-20,
+Ran,Drama,1985-06-01,lots
+Seven,Crime,1995-09
+`,
+			counts: [4]int{1, 1, 0, 2},
+			rows: []row{
+				{2, "Heat", ImportUpdated, ""},
+				{3, "Brazil", ImportCreated, ""},
+				{4, "Ran", ImportFailed, "invalid budget"},
+				{5, "Seven", ImportFailed, "invalid releaseDate format"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(engine, "POST", "/movies:batch"+tt.query, tt.body, bearer(testAuthToken)...)
+			expectStatus(t, w, http.StatusOK)
+			var report ImportReport
+			decode(t, w, &report)
+			if got := [4]int{report.Created, report.Updated, report.Skipped, report.Failed}; got != tt.counts {
+				t.Errorf("counts = %v, want %v", got, tt.counts)
+			}
+			if len(report.Rows) != len(tt.rows) {
+				t.Fatalf("rows = %+v, want %d", report.Rows, len(tt.rows))
+			}
+			for i, want := range tt.rows {
+				got := report.Rows[i]
+				if got.Line != want.line || got.Title != want.title || got.Status != want.status || !strings.HasPrefix(got.Reason, want.reason) {
+					t.Errorf("row %d = %+v, want %+v", i, got, want)
+				}
+				if (got.ID == nil) != (want.status == ImportFailed) {
+					t.Errorf("row %d: id = %v for status %s", i, got.ID, got.Status)
+				}
+			}
+		})
+	}
+
+	// Created movies are queued for enrichment rather than looked up inline.
+	var queued int
+	if err := h.db.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM enrichment_jobs`).Scan(&queued); err != nil {
+		t.Fatal(err)
+	}
+	if queued != 2 {
+		t.Fatalf("queued %d enrichment jobs, want 2", queued)
+	}
+
+	t.Run("CSV without required columns", func(t *testing.T) {
+		expectStatus(t, do(engine, "POST", "/movies:batch?format=csv", "title,genre\nJaws,Thriller\n", bearer(testAuthToken)...), http.StatusUnprocessableEntity)
+	})
+	t.Run("unknown onConflict", func(t *testing.T) {
+		expectStatus(t, do(engine, "POST", "/movies:batch?onConflict=return", "", bearer(testAuthToken)...), http.StatusBadRequest)
+	})
+}
+
+func TestImportSpansChunks(t *testing.T) {
+	h, _ := newTestServer(t, testAuthToken)
+	const rows = importChunkSize + 1
+	var body strings.Builder
+	for i := range rows {
+		fmt.Fprintf(&body, `{"title":"Movie %d","genre":"Drama","releaseDate":"2020-01-01"}`+"\n", i)
+	}
+
+	report, err := ImportMovies(context.Background(), h.db, strings.NewReader(body.String()), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != rows || len(report.Rows) != rows {
+		t.Fatalf("created %d with %d rows, want %d", report.Created, len(report.Rows), rows)
+	}
+	ids := make(map[string]bool, rows)
+	for _, r := range report.Rows {
+		ids[*r.ID] = true
+	}
+	if len(ids) != rows {
+		t.Fatalf("%d distinct IDs, want %d", len(ids), rows)
+	}
+}
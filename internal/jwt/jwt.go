@@ -0,0 +1,268 @@
+// Package jwt implements the small subset of JSON Web Tokens (RFC 7519) the service needs:
+// compact JWS serialization, HS256/RS256/ES256 verification and registered-claim checks.
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformed indicates the token is not a three-part compact JWS.
+	ErrMalformed = errors.New("jwt: malformed token")
+	// ErrSignature indicates the signature did not verify or the algorithm is not accepted.
+	ErrSignature = errors.New("jwt: invalid signature")
+	// ErrExpired indicates the exp claim is in the past.
+	ErrExpired = errors.New("jwt: token expired")
+	// ErrNoExpiry indicates the token has no exp claim; tokens that never expire are not accepted.
+	ErrNoExpiry = errors.New("jwt: token has no expiry")
+	// ErrNotYetValid indicates the nbf claim is in the future.
+	ErrNotYetValid = errors.New("jwt: token not yet valid")
+	// ErrIssuer indicates the iss claim does not match the expected issuer.
+	ErrIssuer = errors.New("jwt: unexpected issuer")
+	// ErrAudience indicates the aud claim does not contain the expected audience.
+	ErrAudience = errors.New("jwt: unexpected audience")
+)
+
+// Audience accepts both the string and the array form of the aud claim.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Claims holds the registered claims plus the scope claims used for authorization.
+type Claims struct {
+	Subject   string   `json:"sub,omitempty"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ID        string   `json:"jti,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	// Scope is the space-delimited OAuth 2.0 form; Scp is the array form some IdPs emit.
+	Scope string   `json:"scope,omitempty"`
+	Scp   []string `json:"scp,omitempty"`
+	// Tenant binds the token to one tenant's catalogue.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Scopes merges the scope and scp claims.
+func (c *Claims) Scopes() []string {
+	scopes := strings.Fields(c.Scope)
+	for _, s := range c.Scp {
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+	Kid string `json:"kid,omitempty"`
+}
+
+var enc = base64.RawURLEncoding
+
+// SignHS256 serializes claims and signs them with HMAC-SHA256.
+func SignHS256(claims any, secret []byte) (string, error) {
+	h, err := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	p, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("jwt: encode claims: %w", err)
+	}
+	signingInput := enc.EncodeToString(h) + "." + enc.EncodeToString(p)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+	return signingInput + "." + enc.EncodeToString(mac.Sum(nil)), nil
+}
+
+// LooksLikeJWT reports whether s has the shape of a compact JWS with a JSON header.
+func LooksLikeJWT(s string) bool {
+	return strings.Count(s, ".") == 2 && strings.HasPrefix(s, "eyJ")
+}
+
+// Verifier checks token signatures, time-based claims and, when configured, issuer and audience.
+type Verifier struct {
+	hmacSecret []byte
+	keys       map[string]crypto.PublicKey
+	issuer     string
+	audience   string
+	leeway     time.Duration
+	now        func() time.Time
+}
+
+// VerifierOption allows customizing the verifier.
+type VerifierOption func(*Verifier)
+
+// WithHMACSecret accepts HS256 tokens signed with secret.
+func WithHMACSecret(secret []byte) VerifierOption {
+	return func(v *Verifier) {
+		v.hmacSecret = secret
+	}
+}
+
+// WithKeys accepts RS256/ES256 tokens signed by one of the keys, indexed by key ID.
+func WithKeys(keys map[string]crypto.PublicKey) VerifierOption {
+	return func(v *Verifier) {
+		v.keys = keys
+	}
+}
+
+// WithIssuer requires the iss claim to equal issuer.
+func WithIssuer(issuer string) VerifierOption {
+	return func(v *Verifier) {
+		v.issuer = issuer
+	}
+}
+
+// WithAudience requires the aud claim to contain audience.
+func WithAudience(audience string) VerifierOption {
+	return func(v *Verifier) {
+		v.audience = audience
+	}
+}
+
+// NewVerifier builds a Verifier; at least one of WithHMACSecret or WithKeys should be given.
+func NewVerifier(opts ...VerifierOption) *Verifier {
+	v := &Verifier{leeway: 30 * time.Second, now: time.Now}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
+
+// NewHS256Verifier returns a Verifier accepting HS256 tokens signed with secret.
+func NewHS256Verifier(secret []byte) *Verifier {
+	return NewVerifier(WithHMACSecret(secret))
+}
+
+// Verify checks the token signature and registered claims and returns the decoded claims.
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	rawHeader, err := enc.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, ErrMalformed
+	}
+	sig, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+
+	if err := v.verifySignature(h, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := enc.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformed
+	}
+
+	now := v.now()
+	if claims.ExpiresAt == 0 {
+		return nil, ErrNoExpiry
+	}
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(v.leeway)) {
+		return nil, ErrExpired
+	}
+	if claims.NotBefore != 0 && now.Add(v.leeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, ErrNotYetValid
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, ErrIssuer
+	}
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return nil, ErrAudience
+	}
+	return &claims, nil
+}
+
+func (v *Verifier) verifySignature(h header, signingInput string, sig []byte) error {
+	if h.Alg == "HS256" {
+		if len(v.hmacSecret) == 0 {
+			return ErrSignature
+		}
+		mac := hmac.New(sha256.New, v.hmacSecret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return ErrSignature
+		}
+		return nil
+	}
+
+	key := v.lookupKey(h.Kid)
+	if key == nil {
+		return ErrSignature
+	}
+	digest := sha256.Sum256([]byte(signingInput))
+	switch h.Alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok || rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return ErrSignature
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return ErrSignature
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrSignature
+		}
+	default:
+		return ErrSignature
+	}
+	return nil
+}
+
+// lookupKey finds the key for kid; tokens without kid are accepted only when exactly one key is configured.
+func (v *Verifier) lookupKey(kid string) crypto.PublicKey {
+	if kid != "" {
+		return v.keys[kid]
+	}
+	if len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k
+		}
+	}
+	return nil
+}
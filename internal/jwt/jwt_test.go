@@ -0,0 +1,63 @@
+package jwt
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestVerifyRegisteredClaims(t *testing.T) {
+	secret := []byte("test-secret")
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name    string
+		claims  Claims
+		opts    []VerifierOption
+		wantErr error
+	}{
+		{name: "valid", claims: Claims{Subject: "svc", ExpiresAt: now.Add(time.Hour).Unix()}},
+		{name: "missing exp", claims: Claims{Subject: "svc"}, wantErr: ErrNoExpiry},
+		{name: "missing exp with nbf", claims: Claims{Subject: "svc", NotBefore: now.Add(-time.Hour).Unix()}, wantErr: ErrNoExpiry},
+		{name: "expired", claims: Claims{ExpiresAt: now.Add(-time.Minute).Unix()}, wantErr: ErrExpired},
+		{name: "expired within leeway", claims: Claims{ExpiresAt: now.Add(-10 * time.Second).Unix()}},
+		{name: "not yet valid", claims: Claims{ExpiresAt: now.Add(time.Hour).Unix(), NotBefore: now.Add(time.Minute).Unix()}, wantErr: ErrNotYetValid},
+		{
+			name:    "wrong issuer",
+			claims:  Claims{Issuer: "other", ExpiresAt: now.Add(time.Hour).Unix()},
+			opts:    []VerifierOption{WithIssuer("robin")},
+			wantErr: ErrIssuer,
+		},
+		{
+			name:    "wrong audience",
+			claims:  Claims{Audience: Audience{"other"}, ExpiresAt: now.Add(time.Hour).Unix()},
+			opts:    []VerifierOption{WithAudience("robin")},
+			wantErr: ErrAudience,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := SignHS256(tt.claims, secret)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := NewVerifier(append([]VerifierOption{WithHMACSecret(secret)}, tt.opts...)...)
+			v.now = func() time.Time { return now }
+
+			_, err = v.Verify(token)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsOtherSecret(t *testing.T) {
+	token, err := SignHS256(Claims{ExpiresAt: time.Now().Add(time.Hour).Unix()}, []byte("a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHS256Verifier([]byte("b")).Verify(token); !errors.Is(err, ErrSignature) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrSignature)
+	}
+}
@@ -0,0 +1,146 @@
+// Package lifecycle runs the HTTP server and background workers and stops them in order on SIGINT or SIGTERM.
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+)
+
+// Server is the part of the Hertz server the manager drives.
+type Server interface {
+	Run() error
+	Shutdown(ctx context.Context) error
+}
+
+// Manager owns the shutdown sequence:
+//  1. report draining, so /readyz fails, and wait drainDelay for load balancers to notice;
+//  2. stop accepting connections and wait for in-flight requests;
+//  3. cancel the workers' context and wait for each to return;
+//  4. run the stop hooks in registration order (e.g. checkpoint and close the database).
+//
+// All steps share one deadline.
+type Manager struct {
+	timeout    time.Duration
+	drainDelay time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	draining atomic.Bool
+	stops    []stopHook
+}
+
+type stopHook struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// New returns a manager whose whole shutdown sequence is bounded by timeout.
+func New(timeout, drainDelay time.Duration) *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{timeout: timeout, drainDelay: drainDelay, ctx: ctx, cancel: cancel}
+}
+
+// Go runs worker in its own goroutine with a context that is cancelled once the server has drained.
+// Workers should finish the item in hand and return promptly when it is.
+func (m *Manager) Go(name string, worker func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		worker(m.ctx)
+		hlog.Infof("lifecycle: %s stopped", name)
+	}()
+}
+
+// Serve runs an auxiliary server, such as the HTTP-to-HTTPS redirect listener, and shuts it down together
+// with the workers. A server that fails to start is logged and does not stop the main one.
+func (m *Manager) Serve(name string, srv Server) {
+	m.Go(name, func(ctx context.Context) {
+		errCh := make(chan error, 1)
+		go func() { errCh <- srv.Run() }()
+		select {
+		case err := <-errCh:
+			hlog.Errorf("lifecycle: %s: %v", name, err)
+			return
+		case <-ctx.Done():
+		}
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			hlog.Errorf("lifecycle: %s shutdown: %v", name, err)
+		}
+	})
+}
+
+// OnStop registers fn to run after the server and workers have stopped. Hooks run in registration order.
+func (m *Manager) OnStop(name string, fn func(context.Context) error) {
+	m.stops = append(m.stops, stopHook{name: name, fn: fn})
+}
+
+// Draining reports whether shutdown has begun.
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// Run serves until SIGINT or SIGTERM, or until the server fails, then performs the shutdown sequence.
+// It returns the server's error, if any.
+func (m *Manager) Run(srv Server) error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Run() }()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	var runErr error
+	select {
+	case sig := <-signals:
+		hlog.Infof("lifecycle: received %s, shutting down within %s", sig, m.timeout)
+	case runErr = <-errCh:
+		hlog.Errorf("lifecycle: server stopped: %v", runErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+	m.draining.Store(true)
+
+	if runErr == nil {
+		if m.drainDelay > 0 {
+			select {
+			case <-time.After(m.drainDelay):
+			case <-ctx.Done():
+			}
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			hlog.Errorf("lifecycle: server shutdown: %v", err)
+		}
+	}
+
+	m.cancel()
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		hlog.Warnf("lifecycle: workers still running at the deadline")
+	}
+
+	for _, hook := range m.stops {
+		if err := hook.fn(ctx); err != nil {
+			hlog.Errorf("lifecycle: %s: %v", hook.name, err)
+		}
+	}
+	hlog.Infof("lifecycle: shutdown complete")
+	return runErr
+}
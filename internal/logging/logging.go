@@ -0,0 +1,192 @@
+// Package logging routes hlog through log/slog as JSON lines and tags each request with an ID.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// slog levels for the hlog levels that slog does not define.
+const (
+	levelTrace  = slog.Level(-8)
+	levelNotice = slog.Level(2)
+	levelFatal  = slog.Level(12)
+)
+
+// ParseLevel converts a level name such as "debug" or "warn" to an hlog level.
+func ParseLevel(s string) (hlog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return hlog.LevelTrace, nil
+	case "debug":
+		return hlog.LevelDebug, nil
+	case "", "info":
+		return hlog.LevelInfo, nil
+	case "notice":
+		return hlog.LevelNotice, nil
+	case "warn", "warning":
+		return hlog.LevelWarn, nil
+	case "error":
+		return hlog.LevelError, nil
+	case "fatal":
+		return hlog.LevelFatal, nil
+	}
+	return hlog.LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+func slogLevel(lv hlog.Level) slog.Level {
+	switch lv {
+	case hlog.LevelTrace:
+		return levelTrace
+	case hlog.LevelDebug:
+		return slog.LevelDebug
+	case hlog.LevelNotice:
+		return levelNotice
+	case hlog.LevelWarn:
+		return slog.LevelWarn
+	case hlog.LevelError:
+		return slog.LevelError
+	case hlog.LevelFatal:
+		return levelFatal
+	}
+	return slog.LevelInfo
+}
+
+// Logger is an hlog.FullLogger that writes JSON lines through log/slog.
+// Records logged with a request context carry its request_id and, when traced, its trace_id and span_id.
+type Logger struct {
+	level  slog.LevelVar
+	logger atomic.Pointer[slog.Logger]
+}
+
+var _ hlog.FullLogger = (*Logger)(nil)
+
+// New returns a Logger writing records at or above level to w.
+func New(w io.Writer, level hlog.Level) *Logger {
+	l := &Logger{}
+	l.level.Set(slogLevel(level))
+	l.SetOutput(w)
+	return l
+}
+
+// Slog returns the underlying slog logger, e.g. for slog.SetDefault.
+func (l *Logger) Slog() *slog.Logger {
+	return l.logger.Load()
+}
+
+// SetLevel implements hlog.Control.
+func (l *Logger) SetLevel(lv hlog.Level) {
+	l.level.Set(slogLevel(lv))
+}
+
+// SetOutput implements hlog.Control.
+func (l *Logger) SetOutput(w io.Writer) {
+	h := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: &l.level, ReplaceAttr: replaceLevel})
+	l.logger.Store(slog.New(contextHandler{h}))
+}
+
+// replaceLevel names the levels slog does not know instead of printing them as offsets like "DEBUG-4".
+func replaceLevel(_ []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.LevelKey {
+		return a
+	}
+	switch a.Value.Any().(slog.Level) {
+	case levelTrace:
+		a.Value = slog.StringValue("TRACE")
+	case levelNotice:
+		a.Value = slog.StringValue("NOTICE")
+	case levelFatal:
+		a.Value = slog.StringValue("FATAL")
+	}
+	return a
+}
+
+func (l *Logger) log(ctx context.Context, lv hlog.Level, msg string) {
+	l.logger.Load().Log(ctx, slogLevel(lv), msg)
+	if lv == hlog.LevelFatal {
+		os.Exit(1)
+	}
+}
+
+func (l *Logger) Trace(v ...any)  { l.log(context.Background(), hlog.LevelTrace, fmt.Sprint(v...)) }
+func (l *Logger) Debug(v ...any)  { l.log(context.Background(), hlog.LevelDebug, fmt.Sprint(v...)) }
+func (l *Logger) Info(v ...any)   { l.log(context.Background(), hlog.LevelInfo, fmt.Sprint(v...)) }
+func (l *Logger) Notice(v ...any) { l.log(context.Background(), hlog.LevelNotice, fmt.Sprint(v...)) }
+func (l *Logger) Warn(v ...any)   { l.log(context.Background(), hlog.LevelWarn, fmt.Sprint(v...)) }
+func (l *Logger) Error(v ...any)  { l.log(context.Background(), hlog.LevelError, fmt.Sprint(v...)) }
+func (l *Logger) Fatal(v ...any)  { l.log(context.Background(), hlog.LevelFatal, fmt.Sprint(v...)) }
+
+func (l *Logger) Tracef(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelTrace, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Debugf(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelDebug, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Infof(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelInfo, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Noticef(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelNotice, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Warnf(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelWarn, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Errorf(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelError, fmt.Sprintf(format, v...))
+}
+func (l *Logger) Fatalf(format string, v ...any) {
+	l.log(context.Background(), hlog.LevelFatal, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) CtxTracef(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelTrace, fmt.Sprintf(format, v...))
+}
+func (l *Logger) CtxDebugf(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelDebug, fmt.Sprintf(format, v...))
+}
+func (l *Logger) CtxInfof(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelInfo, fmt.Sprintf(format, v...))
+}
+func (l *Logger) CtxNoticef(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelNotice, fmt.Sprintf(format, v...))
+}
+func (l *Logger) CtxWarnf(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelWarn, fmt.Sprintf(format, v...))
+}
+func (l *Logger) CtxErrorf(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelError, fmt.Sprintf(format, v...))
+}
+func (l *Logger) CtxFatalf(ctx context.Context, format string, v ...any) {
+	l.log(ctx, hlog.LevelFatal, fmt.Sprintf(format, v...))
+}
+
+// contextHandler adds the request and trace identifiers found in the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// RequestIDHeader carries the request ID in both directions: a well-formed incoming value is kept
+// so that IDs assigned by a proxy stay the same, otherwise a new one is generated.
+const RequestIDHeader = "X-Request-Id"
+
+const (
+	maxRequestIDLength = 128
+	requestIDKey       = "requestID"
+)
+
+type requestIDContextKey struct{}
+
+// RequestID returns the ID assigned to the request by Middleware.
+func RequestID(c *app.RequestContext) string {
+	return c.GetString(requestIDKey)
+}
+
+// RequestIDFromContext returns the request ID carried by a handler's context.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// Middleware assigns each request an ID, returns it in X-Request-Id and passes it to the handlers in ctx,
+// then writes one access log record. Requests answered below 500 are logged with probability sampleRate
+// (1 logs all of them); server errors are always logged.
+func (l *Logger) Middleware(sampleRate float64) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		id := string(c.GetHeader(RequestIDHeader))
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Response.Header.Set(RequestIDHeader, id)
+		ctx = context.WithValue(ctx, requestIDContextKey{}, id)
+
+		start := time.Now()
+		c.Next(ctx)
+
+		status := c.Response.StatusCode()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if sampleRate < 1 && mathrand.Float64() >= sampleRate {
+			return
+		}
+		l.Slog().LogAttrs(ctx, level, "request",
+			slog.String("method", string(c.Method())),
+			slog.String("route", c.FullPath()),
+			slog.String("path", string(c.Path())),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
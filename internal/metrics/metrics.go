@@ -0,0 +1,135 @@
+// Package metrics collects Prometheus metrics for HTTP requests, box office lookups and database queries.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"Robin-Camp/internal/boxoffice"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric served on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by method, route pattern and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	boxOfficeRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "boxoffice_requests_total",
+		Help: "Box office upstream calls by outcome (ok, not_found, circuit_open, error).",
+	}, []string{"outcome"})
+	boxOfficeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "boxoffice_request_duration_seconds",
+		Help:    "Box office upstream call latency by outcome.",
+		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"outcome"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "SQLite statement latency by operation (exec, query, begin, commit, rollback).",
+		Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+	}, []string{"operation"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		boxOfficeRequests, boxOfficeDuration,
+		dbQueryDuration,
+	)
+}
+
+// MustRegister adds collectors to Registry, panicking if one is already registered.
+func MustRegister(cs ...prometheus.Collector) {
+	Registry.MustRegister(cs...)
+}
+
+// Handler serves Registry in the Prometheus text exposition format.
+func Handler() app.HandlerFunc {
+	return adaptor.HertzHandler(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records the count and latency of every request under its route pattern (e.g. /movies/:title),
+// so path parameters do not create a series per movie. Requests that match no route are recorded as "unmatched",
+// and methods outside the standard set as "OTHER", so clients cannot create series of their own.
+func Middleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		start := time.Now()
+		c.Next(ctx)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		labels := prometheus.Labels{
+			"method": methodLabel(string(c.Method())),
+			"route":  route,
+			"status": strconv.Itoa(c.Response.StatusCode()),
+		}
+		httpRequests.With(labels).Inc()
+		httpDuration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
+
+var standardMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true, http.MethodPatch: true,
+	http.MethodDelete: true, http.MethodConnect: true, http.MethodOptions: true, http.MethodTrace: true,
+}
+
+func methodLabel(method string) string {
+	if standardMethods[method] {
+		return method
+	}
+	return "OTHER"
+}
+
+// BoxOfficeClient matches the upstream client used by the handlers.
+type BoxOfficeClient interface {
+	GetMovieBoxOffice(ctx context.Context, title string) (*boxoffice.BoxOffice, error)
+}
+
+type instrumentedBoxOffice struct {
+	next BoxOfficeClient
+}
+
+// InstrumentBoxOffice wraps client so that every call is counted and timed by outcome.
+func InstrumentBoxOffice(client BoxOfficeClient) BoxOfficeClient {
+	return instrumentedBoxOffice{next: client}
+}
+
+func (c instrumentedBoxOffice) GetMovieBoxOffice(ctx context.Context, title string) (*boxoffice.BoxOffice, error) {
+	start := time.Now()
+	bo, err := c.next.GetMovieBoxOffice(ctx, title)
+
+	outcome := "ok"
+	switch {
+	case errors.Is(err, boxoffice.ErrNotFound):
+		outcome = "not_found"
+	case errors.Is(err, boxoffice.ErrCircuitOpen):
+		outcome = "circuit_open"
+	case err != nil:
+		outcome = "error"
+	}
+	boxOfficeRequests.WithLabelValues(outcome).Inc()
+	boxOfficeDuration.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+	return bo, err
+}
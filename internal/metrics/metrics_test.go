@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"Robin-Camp/internal/boxoffice"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type stubBoxOffice struct{ err error }
+
+func (s stubBoxOffice) GetMovieBoxOffice(ctx context.Context, title string) (*boxoffice.BoxOffice, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &boxoffice.BoxOffice{}, nil
+}
+
+func TestInstrumentBoxOffice(t *testing.T) {
+	tests := []struct {
+		err     error
+		outcome string
+	}{
+		{nil, "ok"},
+		{fmt.Errorf("%w: 404", boxoffice.ErrNotFound), "not_found"},
+		{boxoffice.ErrCircuitOpen, "circuit_open"},
+		{errors.New("connection refused"), "error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.outcome, func(t *testing.T) {
+			before := testutil.ToFloat64(boxOfficeRequests.WithLabelValues(tt.outcome))
+			_, err := InstrumentBoxOffice(stubBoxOffice{tt.err}).GetMovieBoxOffice(context.Background(), "Heat")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v passed through", err, tt.err)
+			}
+			if got := testutil.ToFloat64(boxOfficeRequests.WithLabelValues(tt.outcome)) - before; got != 1 {
+				t.Fatalf("%s calls counted %v times, want 1", tt.outcome, got)
+			}
+		})
+	}
+}
+
+func TestMiddlewareLabels(t *testing.T) {
+	engine := route.NewEngine(config.NewOptions(nil))
+	engine.Use(Middleware())
+	engine.GET("/movies/:title", func(ctx context.Context, c *app.RequestContext) {
+		c.Status(200)
+	})
+
+	count := func(method, route, status string) float64 {
+		return testutil.ToFloat64(httpRequests.WithLabelValues(method, route, status))
+	}
+	tests := []struct {
+		method, path          string
+		wantMethod, wantRoute string
+		wantStatus            string
+	}{
+		{"GET", "/movies/Heat", "GET", "/movies/:title", "200"},
+		{"GET", "/unknown/path", "GET", "unmatched", "404"},
+		{"BREW", "/movies/Heat", "OTHER", "unmatched", "404"},
+	}
+	for _, tt := range tests {
+		before := count(tt.wantMethod, tt.wantRoute, tt.wantStatus)
+		ut.PerformRequest(engine, tt.method, tt.path, nil)
+		if got := count(tt.wantMethod, tt.wantRoute, tt.wantStatus) - before; got != 1 {
+			t.Errorf("%s %s: recorded %v under (%s, %s, %s), want 1", tt.method, tt.path, got, tt.wantMethod, tt.wantRoute, tt.wantStatus)
+		}
+	}
+}
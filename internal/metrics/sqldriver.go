@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// conn is the set of optional driver interfaces the SQLite driver implements; the wrapper forwards all of them
+// so database/sql keeps using the context-aware fast paths.
+type conn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// WrapDriver returns a driver whose connections time every Exec, Query, Begin, Commit and Rollback
+// in db_query_duration_seconds. Query durations cover execution up to the first row, not reading the result set.
+func WrapDriver(d driver.Driver) driver.Driver {
+	return instrumentedDriver{Driver: d}
+}
+
+type instrumentedDriver struct {
+	driver.Driver
+}
+
+func (d instrumentedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	full, ok := c.(conn)
+	if !ok {
+		return c, nil
+	}
+	return instrumentedConn{conn: full}, nil
+}
+
+type instrumentedConn struct {
+	conn
+}
+
+func (c instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	defer observeQuery("exec", time.Now())
+	return c.conn.ExecContext(ctx, query, args)
+}
+
+func (c instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	defer observeQuery("query", time.Now())
+	return c.conn.QueryContext(ctx, query, args)
+}
+
+func (c instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	start := time.Now()
+	tx, err := c.conn.BeginTx(ctx, opts)
+	observeQuery("begin", start)
+	if err != nil {
+		return nil, err
+	}
+	return instrumentedTx{Tx: tx}, nil
+}
+
+type instrumentedTx struct {
+	driver.Tx
+}
+
+func (t instrumentedTx) Commit() error {
+	defer observeQuery("commit", time.Now())
+	return t.Tx.Commit()
+}
+
+func (t instrumentedTx) Rollback() error {
+	defer observeQuery("rollback", time.Now())
+	return t.Tx.Rollback()
+}
+
+func observeQuery(op string, start time.Time) {
+	dbQueryDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
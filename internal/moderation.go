@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	moderationVisible  = "visible"
+	moderationPending  = "pending"
+	moderationApproved = "approved"
+	moderationRejected = "rejected"
+
+	defaultReportThreshold = 3
+	maxReportReasonLength  = 500
+
+	// visibleRatingClause filters out ratings that are awaiting review or were rejected.
+	visibleRatingClause = "moderation_status NOT IN ('pending', 'rejected')"
+)
+
+// reviewVerdict runs the configured content filter over a review. It returns pending with the filter's reasons for
+// flagged reviews and visible otherwise; only the former is stored, through holdFlaggedReview.
+func (h *Handler) reviewVerdict(ctx context.Context, r *ReviewSubmit) (string, *string, error) {
+	if h.contentFilter == nil {
+		return moderationVisible, nil, nil
+	}
+	v, err := h.contentFilter.Check(ctx, r.Title+"\n"+r.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("content filter: %w", err)
+	}
+	if !v.Flagged {
+		return moderationVisible, nil, nil
+	}
+	reason := strings.Join(v.Reasons, "; ")
+	return moderationPending, &reason, nil
+}
+
+// holdFlaggedReview moves a rating whose resubmitted review the content filter flagged back to pending. A clean
+// resubmission never lowers the status: pending and rejected reviews stay hidden until an admin decides, and a
+// rejected one is not reopened by editing it. An approved review whose text changed is already back to visible
+// (see upsertRating), so reports count against the new text.
+func holdFlaggedReview(ctx context.Context, tx *sql.Tx, movieID, raterID string, reason *string) error {
+	_, err := tx.ExecContext(ctx, `UPDATE ratings SET moderation_status = ?, moderation_reason = ?, moderated_at = STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE movie_id = ? AND rater_id = ? AND moderation_status IN (?, ?)`,
+		moderationPending, reason, movieID, raterID, moderationVisible, moderationApproved)
+	if err != nil {
+		return fmt.Errorf("update moderation status: %w", err)
+	}
+	return nil
+}
+
+// setModerationStatus records a moderation decision. It stamps moderated_at because the decision changes which
+// ratings the aggregates count, so their Last-Modified must move as well.
+func setModerationStatus(ctx context.Context, tx *sql.Tx, movieID, raterID, status string, reason *string) error {
+	res, err := tx.ExecContext(ctx, `UPDATE ratings SET moderation_status = ?, moderation_reason = ?, moderated_at = STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now') WHERE movie_id = ? AND rater_id = ?`, status, reason, movieID, raterID)
+	if err != nil {
+		return fmt.Errorf("update moderation status: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errRatingNotFound
+	}
+	return nil
+}
+
+// reportRating godoc
+// @Summary      Report a rating or review
+// @Description  Flags another rater's rating (and its review) for moderation. Each rater can report an item once; once the number of distinct reports reaches the configured threshold the item is hidden until an admin decides.
+// @Tags         Moderation
+// @Accept       json
+// @Produce      json
+// @Security     RaterId
+// @Param        title       path      string         true   "Movie title"
+// @Param        raterId     path      string         true   "Author of the reported rating"
+// @Param        X-Rater-Id  header    string         true   "Unique rater identifier of the reporter"
+// @Param        report      body      ReportSubmit   false  "Optional reason"
+// @Success      202         {object}  ReportResult
+// @Failure      400         {object}  Error  "Bad request"
+// @Failure      401         {object}  Error  "Unauthorized (missing or invalid X-Rater-Id)"
+// @Failure      404         {object}  Error  "Movie or rating not found"
+// @Failure      422         {object}  Error  "Cannot report own rating"
+// @Failure      429         {object}  Error  "Rate limit exceeded for this rater or client IP"
+// @Failure      500         {object}  Error  "Internal server error"
+// @Router       /movies/{title}/ratings/{raterId}/reports [post]
+func (h *Handler) reportRating(ctx context.Context, c *app.RequestContext) {
+	title := strings.TrimSpace(c.Param("title"))
+	authorID := c.Param("raterId")
+	reporterID := currentRater(c)
+
+	var payload ReportSubmit
+	if len(c.Request.Body()) > 0 {
+		if err := bindJSON(c, &payload); err != nil {
+			invalidBody(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+	payload.Reason = strings.TrimSpace(payload.Reason)
+	if len(payload.Reason) > maxReportReasonLength {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: fmt.Sprintf("reason exceeds %d bytes", maxReportReasonLength)})
+		return
+	}
+	if reporterID == authorID {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "cannot report own rating"})
+		return
+	}
+
+	var movieID, status string
+	err := h.db.QueryRowContext(ctx, `SELECT r.movie_id, r.moderation_status FROM ratings r JOIN movies m ON m.id = r.movie_id WHERE m.tenant_id = ? AND m.title = ? AND r.rater_id = ?`, currentTenant(c), title, authorID).Scan(&movieID, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "rating not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	res := ReportResult{RaterID: authorID, Status: status}
+	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO moderation_reports (movie_id, rater_id, reporter_id, reason) VALUES (?, ?, ?, ?)`, movieID, authorID, reporterID, nullIfEmpty(payload.Reason)); err != nil {
+			return fmt.Errorf("insert report: %w", err)
+		}
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM moderation_reports WHERE movie_id = ? AND rater_id = ?`, movieID, authorID).Scan(&res.ReportCount); err != nil {
+			return fmt.Errorf("count reports: %w", err)
+		}
+		// Items an admin approved stay visible until their review text changes; everything else is hidden once
+		// enough raters complain.
+		if status == moderationVisible && res.ReportCount >= int64(h.reportThreshold) {
+			reason := fmt.Sprintf("reported by %d raters", res.ReportCount)
+			res.Status = moderationPending
+			return setModerationStatus(ctx, tx, movieID, authorID, moderationPending, &reason)
+		}
+		return nil
+	}); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+// listModerationQueue godoc
+// @Summary      List the moderation queue
+// @Description  Returns ratings awaiting a moderation decision (or, with status, items in another moderation state), oldest first.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "Moderation status: pending (default), approved, rejected or visible"
+// @Param        limit   query     int     false  "Maximum number of items to return (default 50, max 500)"
+// @Param        cursor  query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200     {object}  ModerationPage
+// @Failure      400     {object}  Error  "Bad request"
+// @Failure      401     {object}  Error  "Unauthorized"
+// @Failure      403     {object}  Error  "Forbidden (token lacks ratings:admin)"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /admin/moderation/queue [get]
+func (h *Handler) listModerationQueue(ctx context.Context, c *app.RequestContext) {
+	status := c.Query("status")
+	switch status {
+	case "":
+		status = moderationPending
+	case moderationPending, moderationApproved, moderationRejected, moderationVisible:
+	default:
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid status"})
+		return
+	}
+
+	limit := defaultEventLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxEventLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+	offset := 0
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.Atoi(cursorStr)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		offset = v
+	}
+
+	rows, err := h.db.QueryContext(ctx, `SELECT r.movie_id, m.title, r.rater_id, r.rating, r.review_title, r.review_body, r.moderation_status, r.moderation_reason, (SELECT COUNT(*) FROM moderation_reports p WHERE p.movie_id = r.movie_id AND p.rater_id = r.rater_id), r.updated_at FROM ratings r JOIN movies m ON m.id = r.movie_id WHERE m.tenant_id = ? AND r.moderation_status = ? ORDER BY r.updated_at, r.movie_id, r.rater_id LIMIT ? OFFSET ?`,
+		currentTenant(c), status, limit+1, offset,
+	)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	defer rows.Close()
+
+	items := []ModerationItem{}
+	for rows.Next() {
+		var it ModerationItem
+		if err := rows.Scan(&it.MovieID, &it.MovieTitle, &it.RaterID, &it.Rating, &it.ReviewTitle, &it.ReviewBody, &it.Status, &it.Reason, &it.ReportCount, &it.UpdatedAt); err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	var nextCursor *string
+	if len(items) > limit {
+		items = items[:limit]
+		next := strconv.Itoa(offset + limit)
+		nextCursor = &next
+	}
+	c.JSON(http.StatusOK, ModerationPage{Items: items, NextCursor: nextCursor})
+}
+
+// decideModeration godoc
+// @Summary      Approve or reject a moderated item
+// @Description  Approving makes the rating and its review visible again and protects it from report-based hiding until the review text is edited; rejecting hides it from aggregates and review listings.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        movieId   path      string              true   "Movie ID"
+// @Param        raterId   path      string              true   "Author of the rating"
+// @Param        decision  path      string              true   "approve or reject"
+// @Param        body      body      ModerationDecision  false  "Optional note recorded as the moderation reason"
+// @Success      200       {object}  ModerationItem
+// @Failure      400       {object}  Error  "Bad request"
+// @Failure      401       {object}  Error  "Unauthorized"
+// @Failure      403       {object}  Error  "Forbidden (token lacks ratings:admin)"
+// @Failure      404       {object}  Error  "Rating not found"
+// @Failure      500       {object}  Error  "Internal server error"
+// @Router       /admin/moderation/items/{movieId}/{raterId}/{decision} [post]
+func (h *Handler) decideModeration(ctx context.Context, c *app.RequestContext) {
+	movieID := c.Param("movieId")
+	raterID := c.Param("raterId")
+
+	var status string
+	switch c.Param("decision") {
+	case "approve":
+		status = moderationApproved
+	case "reject":
+		status = moderationRejected
+	default:
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "decision must be approve or reject"})
+		return
+	}
+
+	var payload ModerationDecision
+	if len(c.Request.Body()) > 0 {
+		if err := bindJSON(c, &payload); err != nil {
+			invalidBody(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	var inTenant bool
+	if err := h.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM movies WHERE id = ? AND tenant_id = ?)`, movieID, currentTenant(c)).Scan(&inTenant); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	if !inTenant {
+		writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "rating not found"})
+		return
+	}
+
+	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		return setModerationStatus(ctx, tx, movieID, raterID, status, nullIfEmpty(strings.TrimSpace(payload.Note)))
+	}); err != nil {
+		if errors.Is(err, errRatingNotFound) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "rating not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	var it ModerationItem
+	err := h.db.QueryRowContext(ctx, `SELECT r.movie_id, m.title, r.rater_id, r.rating, r.review_title, r.review_body, r.moderation_status, r.moderation_reason, (SELECT COUNT(*) FROM moderation_reports p WHERE p.movie_id = r.movie_id AND p.rater_id = r.rater_id), r.updated_at FROM ratings r JOIN movies m ON m.id = r.movie_id WHERE r.movie_id = ? AND r.rater_id = ?`, movieID, raterID).
+		Scan(&it.MovieID, &it.MovieTitle, &it.RaterID, &it.Rating, &it.ReviewTitle, &it.ReviewBody, &it.Status, &it.Reason, &it.ReportCount, &it.UpdatedAt)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, it)
+}
@@ -0,0 +1,95 @@
+package moderation
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Verdict is the outcome of running a Filter over a piece of text.
+type Verdict struct {
+	Flagged bool
+	Reasons []string
+}
+
+// Filter inspects user-submitted text and reports whether it should be held for moderation.
+type Filter interface {
+	Check(ctx context.Context, text string) (Verdict, error)
+}
+
+// WordList flags text containing any of a fixed set of words, ignoring case.
+// Plain ASCII words match whole tokens; phrases and non-ASCII entries (e.g. CJK,
+// which is not space-delimited) match as substrings.
+type WordList struct {
+	words   map[string]struct{}
+	phrases []string
+}
+
+// NewWordList builds a WordList from the given words; blank entries are skipped.
+func NewWordList(words []string) *WordList {
+	w := &WordList{words: make(map[string]struct{}, len(words))}
+	for _, word := range words {
+		word = strings.ToLower(strings.TrimSpace(word))
+		switch {
+		case word == "":
+		case strings.IndexFunc(word, func(r rune) bool { return r > unicode.MaxASCII || unicode.IsSpace(r) }) >= 0:
+			w.phrases = append(w.phrases, word)
+		default:
+			w.words[word] = struct{}{}
+		}
+	}
+	return w
+}
+
+// LoadWordList reads one word per line from path. Lines starting with '#' are comments.
+func LoadWordList(path string) (*WordList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("moderation: open word list: %w", err)
+	}
+	defer f.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("moderation: read word list: %w", err)
+	}
+	return NewWordList(words), nil
+}
+
+// Check flags text that contains a listed word as a whole token.
+func (w *WordList) Check(_ context.Context, text string) (Verdict, error) {
+	var v Verdict
+	if w == nil || (len(w.words) == 0 && len(w.phrases) == 0) {
+		return v, nil
+	}
+	lower := strings.ToLower(text)
+	seen := make(map[string]bool)
+	tokens := strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, tok := range tokens {
+		if _, ok := w.words[tok]; ok && !seen[tok] {
+			seen[tok] = true
+			v.Flagged = true
+			v.Reasons = append(v.Reasons, "blocked word: "+tok)
+		}
+	}
+	for _, phrase := range w.phrases {
+		if strings.Contains(lower, phrase) {
+			v.Flagged = true
+			v.Reasons = append(v.Reasons, "blocked word: "+phrase)
+		}
+	}
+	return v, nil
+}
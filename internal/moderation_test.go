@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"Robin-Camp/internal/ratelimit"
+)
+
+func TestReportsAfterApproval(t *testing.T) {
+	h, engine := newTestServer(t, testAuthToken, WithReportThreshold(2))
+	createTestMovie(t, engine, "Heat", "")
+	review := func(body string) string {
+		return `{"rating":4,"review":{"title":"Tense","body":"` + body + `"}}`
+	}
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", review("A fine heist film"), "X-Rater-Id", "author"), http.StatusCreated)
+	var movieID string
+	if err := h.db.QueryRowContext(context.Background(), `SELECT id FROM movies WHERE title = 'Heat'`).Scan(&movieID); err != nil {
+		t.Fatal(err)
+	}
+
+	report := func(t *testing.T, reporter, wantStatus string) {
+		t.Helper()
+		w := do(engine, "POST", "/movies/Heat/ratings/author/reports", "", "X-Rater-Id", reporter)
+		expectStatus(t, w, http.StatusAccepted)
+		var res ReportResult
+		decode(t, w, &res)
+		if res.Status != wantStatus {
+			t.Fatalf("status after report by %s = %s, want %s", reporter, res.Status, wantStatus)
+		}
+	}
+
+	report(t, "r1", moderationVisible)
+	report(t, "r2", moderationPending)
+	expectStatus(t, do(engine, "POST", "/admin/moderation/items/"+movieID+"/author/approve", "", bearer(testAuthToken)...), http.StatusOK)
+	report(t, "r3", moderationApproved)
+
+	t.Run("resubmitting the same text keeps the approval", func(t *testing.T) {
+		expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", review("A fine heist film"), "X-Rater-Id", "author"), http.StatusOK)
+		report(t, "r4", moderationApproved)
+	})
+
+	t.Run("edited text can be reported again", func(t *testing.T) {
+		expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", review("Something else entirely"), "X-Rater-Id", "author"), http.StatusOK)
+		report(t, "r5", moderationPending)
+	})
+}
+
+func TestReportsAndVotesAreRateLimited(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken, WithRatingRateLimits(ratelimit.New(0, 0), ratelimit.New(1, 1)))
+	createTestMovie(t, engine, "Heat", "")
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":4,"review":{"body":"Tense"}}`, "X-Rater-Id", "author"), http.StatusCreated)
+
+	// Each request comes from a fresh rater ID, so only the per-IP bucket stops them.
+	for i, path := range []string{"/movies/Heat/ratings/author/reports", "/movies/Heat/reviews/author/helpful"} {
+		w := do(engine, "POST", path, "", "X-Rater-Id", "sock"+strconv.Itoa(i))
+		expectStatus(t, w, http.StatusTooManyRequests)
+		if w.Header().Get("Retry-After") == "" {
+			t.Errorf("%s: missing Retry-After", path)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package internal
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"Robin-Camp/internal/jwt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const defaultOAuthTokenTTL = 15 * time.Minute
+
+var (
+	// ErrOAuthClientNotFound is returned when no client matches the given ID or name.
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+	// ErrOAuthClientNameTaken is returned when a client with the same name already exists.
+	ErrOAuthClientNameTaken = errors.New("oauth client name already in use")
+)
+
+// WithOAuthServer enables the built-in client-credentials authorization server. Access tokens are HS256 JWTs
+// signed with secret, carry issuer as iss and live for ttl (non-positive keeps the default).
+func WithOAuthServer(secret []byte, issuer string, ttl time.Duration) HandlerOption {
+	return func(h *Handler) {
+		if len(secret) == 0 {
+			return
+		}
+		h.oauthSecret = secret
+		h.oauthIssuer = issuer
+		if ttl > 0 {
+			h.oauthTTL = ttl
+		}
+		h.oauthVerifier = jwt.NewVerifier(jwt.WithHMACSecret(secret), jwt.WithIssuer(issuer))
+	}
+}
+
+// CreateOAuthClient registers a client and returns it with the plaintext secret, which is not retrievable later.
+// A non-empty tenant binds the client, and every token issued to it, to that tenant, which rules out the global scopes.
+func CreateOAuthClient(ctx context.Context, db *sql.DB, name string, scopes []string, tenant string) (*OAuthClientSecret, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if err := checkScopes(scopes, tenant); err != nil {
+		return nil, err
+	}
+
+	idBytes := make([]byte, 8)
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, err
+	}
+	clientID := "cli_" + hex.EncodeToString(idBytes)
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	err := WithTx(ctx, db, func(ctx context.Context, tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM oauth_clients WHERE name = ?)`, name).Scan(&exists); err != nil {
+			return err
+		}
+		if exists {
+			return ErrOAuthClientNameTaken
+		}
+		if err := checkTenantExists(ctx, tx, tenant); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO oauth_clients (client_id, name, secret_hash, scopes, tenant_id) VALUES (?, ?, ?, ?, ?)`,
+			clientID, name, hashAPIKey(secret), strings.Join(scopes, " "), nullIfEmpty(tenant),
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cl, err := getOAuthClient(ctx, db, clientID)
+	if err != nil {
+		return nil, err
+	}
+	return &OAuthClientSecret{OAuthClient: *cl, ClientSecret: secret}, nil
+}
+
+// ListOAuthClients returns registered clients ordered by creation time, including revoked ones. A non-empty tenant
+// restricts the list to clients bound to it.
+func ListOAuthClients(ctx context.Context, db *sql.DB, tenant string, limit, offset int) ([]OAuthClient, error) {
+	rows, err := db.QueryContext(ctx, `SELECT client_id, name, scopes, tenant_id, created_at, revoked_at FROM oauth_clients
+		WHERE (? = '' OR tenant_id = ?) ORDER BY created_at, client_id LIMIT ? OFFSET ?`, tenant, tenant, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []OAuthClient{}
+	for rows.Next() {
+		cl, err := scanOAuthClient(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *cl)
+	}
+	return res, rows.Err()
+}
+
+// RevokeOAuthClient disables the client identified by ID or name; tokens it already holds stop working too.
+// A non-empty tenant only matches clients bound to it.
+func RevokeOAuthClient(ctx context.Context, db *sql.DB, ref, tenant string) error {
+	res, err := db.ExecContext(ctx, `UPDATE oauth_clients SET revoked_at = STRFTIME('%Y-%m-%dT%H:%M:%fZ', 'now')
+		WHERE (client_id = ? OR name = ?) AND (? = '' OR tenant_id = ?) AND revoked_at IS NULL`, ref, ref, tenant, tenant)
+	if err != nil {
+		return fmt.Errorf("revoke oauth client: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+func scanOAuthClient(row rowScanner) (*OAuthClient, error) {
+	var cl OAuthClient
+	var scopes string
+	if err := row.Scan(&cl.ClientID, &cl.Name, &scopes, &cl.Tenant, &cl.CreatedAt, &cl.RevokedAt); err != nil {
+		return nil, err
+	}
+	cl.Scopes = strings.Fields(scopes)
+	return &cl, nil
+}
+
+func getOAuthClient(ctx context.Context, db *sql.DB, ref string) (*OAuthClient, error) {
+	cl, err := scanOAuthClient(db.QueryRowContext(ctx, `SELECT client_id, name, scopes, tenant_id, created_at, revoked_at FROM oauth_clients WHERE client_id = ? OR name = ?`, ref, ref))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOAuthClientNotFound
+	}
+	return cl, err
+}
+
+// authenticateClient checks client credentials sent via HTTP Basic auth or as client_id/client_secret form fields.
+func (h *Handler) authenticateClient(ctx context.Context, c *app.RequestContext) (*OAuthClient, bool) {
+	clientID, secret := c.PostForm("client_id"), c.PostForm("client_secret")
+	if raw, ok := strings.CutPrefix(string(c.GetHeader("Authorization")), "Basic "); ok {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, false
+		}
+		id, pw, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return nil, false
+		}
+		// RFC 6749 section 2.3.1 form-encodes both parts before Basic encoding.
+		if clientID, err = url.QueryUnescape(id); err != nil {
+			return nil, false
+		}
+		if secret, err = url.QueryUnescape(pw); err != nil {
+			return nil, false
+		}
+	}
+	if clientID == "" || secret == "" {
+		return nil, false
+	}
+
+	var hash string
+	var revokedAt *string
+	if err := h.db.QueryRowContext(ctx, `SELECT secret_hash, revoked_at FROM oauth_clients WHERE client_id = ?`, clientID).Scan(&hash, &revokedAt); err != nil {
+		return nil, false
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(secret)), []byte(hash)) != 1 || revokedAt != nil {
+		return nil, false
+	}
+	cl, err := getOAuthClient(ctx, h.db, clientID)
+	if err != nil {
+		return nil, false
+	}
+	return cl, true
+}
+
+// verifyOAuthToken checks a token issued by this server. handled reports whether the token was signed with
+// the OAuth secret, in which case claims is nil if it expired, was revoked or belongs to a revoked client.
+func (h *Handler) verifyOAuthToken(ctx context.Context, token string) (claims *jwt.Claims, handled bool) {
+	if h.oauthVerifier == nil {
+		return nil, false
+	}
+	claims, err := h.oauthVerifier.Verify(token)
+	if errors.Is(err, jwt.ErrSignature) || errors.Is(err, jwt.ErrMalformed) {
+		return nil, false
+	}
+	if err != nil {
+		return nil, true
+	}
+
+	var active bool
+	if err := h.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM oauth_clients WHERE client_id = ? AND revoked_at IS NULL) AND NOT EXISTS(SELECT 1 FROM oauth_revoked_tokens WHERE jti = ?)`,
+		claims.Subject, claims.ID,
+	).Scan(&active); err != nil || !active {
+		return nil, true
+	}
+	return claims, true
+}
+
+func oauthError(c *app.RequestContext, status int, code, description string) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, OAuthError{Error: code, ErrorDescription: description})
+}
+
+func (h *Handler) invalidClient(c *app.RequestContext) {
+	if strings.HasPrefix(string(c.GetHeader("Authorization")), "Basic ") {
+		c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	oauthError(c, http.StatusUnauthorized, "invalid_client", "client authentication failed")
+}
+
+// issueOAuthToken godoc
+// @Summary      Issue an access token (client credentials)
+// @Description  OAuth 2.0 token endpoint for the client_credentials grant. Authenticate with HTTP Basic or client_id/client_secret form fields. The returned JWT is accepted as a Bearer token on protected routes.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        grant_type     formData  string  true   "Must be client_credentials"
+// @Param        scope          formData  string  false  "Space-separated subset of the client's scopes (default: all)"
+// @Param        client_id      formData  string  false  "Client ID when not using HTTP Basic"
+// @Param        client_secret  formData  string  false  "Client secret when not using HTTP Basic"
+// @Success      200  {object}  OAuthToken
+// @Failure      400  {object}  OAuthError  "invalid_request, unsupported_grant_type or invalid_scope"
+// @Failure      401  {object}  OAuthError  "invalid_client"
+// @Failure      501  {object}  Error       "OAuth server is not configured"
+// @Router       /oauth/token [post]
+func (h *Handler) issueOAuthToken(ctx context.Context, c *app.RequestContext) {
+	if h.oauthVerifier == nil {
+		writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: "oauth server is not configured"})
+		return
+	}
+	client, ok := h.authenticateClient(ctx, c)
+	if !ok {
+		h.invalidClient(c)
+		return
+	}
+	switch c.PostForm("grant_type") {
+	case "client_credentials":
+	case "":
+		oauthError(c, http.StatusBadRequest, "invalid_request", "grant_type is required")
+		return
+	default:
+		oauthError(c, http.StatusBadRequest, "unsupported_grant_type", "only client_credentials is supported")
+		return
+	}
+
+	scopes := client.Scopes
+	if requested := strings.Fields(c.PostForm("scope")); len(requested) > 0 {
+		for _, s := range requested {
+			if !slices.Contains(client.Scopes, s) {
+				oauthError(c, http.StatusBadRequest, "invalid_scope", "scope not granted to client: "+s)
+				return
+			}
+		}
+		scopes = requested
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	now := time.Now()
+	token, err := jwt.SignHS256(jwt.Claims{
+		Subject:   client.ClientID,
+		Issuer:    h.oauthIssuer,
+		ID:        hex.EncodeToString(jti),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(h.oauthTTL).Unix(),
+		Scope:     strings.Join(scopes, " "),
+		Tenant:    deref(client.Tenant),
+	}, h.oauthSecret)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, OAuthToken{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(h.oauthTTL / time.Second),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// introspectOAuthToken godoc
+// @Summary      Introspect an access token
+// @Description  RFC 7662 token introspection for tokens issued by this server. Callers authenticate as a registered client. Unknown, expired and revoked tokens report active=false.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        token  formData  string  true  "Access token"
+// @Success      200  {object}  OAuthIntrospection
+// @Failure      400  {object}  OAuthError  "invalid_request"
+// @Failure      401  {object}  OAuthError  "invalid_client"
+// @Failure      501  {object}  Error       "OAuth server is not configured"
+// @Router       /oauth/introspect [post]
+func (h *Handler) introspectOAuthToken(ctx context.Context, c *app.RequestContext) {
+	if h.oauthVerifier == nil {
+		writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: "oauth server is not configured"})
+		return
+	}
+	if _, ok := h.authenticateClient(ctx, c); !ok {
+		h.invalidClient(c)
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	claims, _ := h.verifyOAuthToken(ctx, token)
+	if claims == nil {
+		c.JSON(http.StatusOK, OAuthIntrospection{Active: false})
+		return
+	}
+	c.JSON(http.StatusOK, OAuthIntrospection{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.Subject,
+		Sub:       claims.Subject,
+		Iss:       claims.Issuer,
+		Exp:       claims.ExpiresAt,
+		Iat:       claims.IssuedAt,
+		Jti:       claims.ID,
+		TokenType: "Bearer",
+	})
+}
+
+// revokeOAuthToken godoc
+// @Summary      Revoke an access token
+// @Description  RFC 7009 token revocation. A client can only revoke its own tokens; unknown or foreign tokens are ignored and still return 200.
+// @Tags         OAuth
+// @Accept       x-www-form-urlencoded
+// @Param        token  formData  string  true  "Access token"
+// @Success      200  "Token revoked or ignored"
+// @Failure      400  {object}  OAuthError  "invalid_request"
+// @Failure      401  {object}  OAuthError  "invalid_client"
+// @Failure      501  {object}  Error       "OAuth server is not configured"
+// @Router       /oauth/revoke [post]
+func (h *Handler) revokeOAuthToken(ctx context.Context, c *app.RequestContext) {
+	if h.oauthVerifier == nil {
+		writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: "oauth server is not configured"})
+		return
+	}
+	client, ok := h.authenticateClient(ctx, c)
+	if !ok {
+		h.invalidClient(c)
+		return
+	}
+	token := c.PostForm("token")
+	if token == "" {
+		oauthError(c, http.StatusBadRequest, "invalid_request", "token is required")
+		return
+	}
+
+	claims, _ := h.verifyOAuthToken(ctx, token)
+	if claims == nil || claims.Subject != client.ClientID || claims.ID == "" {
+		c.Status(http.StatusOK)
+		return
+	}
+	now := time.Now().UTC().Format(sqliteTimeLayout)
+	expires := time.Unix(claims.ExpiresAt, 0).UTC().Format(sqliteTimeLayout)
+	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		// Entries are only needed until the token would have expired anyway.
+		if _, err := tx.ExecContext(ctx, `DELETE FROM oauth_revoked_tokens WHERE expires_at < ?`, now); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO oauth_revoked_tokens (jti, expires_at) VALUES (?, ?)`, claims.ID, expires)
+		return err
+	}); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// createOAuthClient godoc
+// @Summary      Register an OAuth client
+// @Description  Registers a client for the client_credentials grant. The client secret is only returned in this response.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        client  body      OAuthClientCreate  true  "Client name and the scopes it may request"
+// @Success      201     {object}  OAuthClientSecret
+// @Failure      400     {object}  Error  "Bad request"
+// @Failure      401     {object}  Error  "Unauthorized"
+// @Failure      403     {object}  Error  "Forbidden (token lacks apikeys:admin or a requested scope, or is bound to another tenant)"
+// @Failure      409     {object}  Error  "Name already in use"
+// @Failure      422     {object}  Error  "Unknown scope or tenant, or global scope for a tenant-bound client"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /admin/oauth-clients [post]
+func (h *Handler) createOAuthClient(ctx context.Context, c *app.RequestContext) {
+	var payload OAuthClientCreate
+	if err := bindJSON(c, &payload); err != nil {
+		invalidBody(c, http.StatusBadRequest, err)
+		return
+	}
+	if strings.TrimSpace(payload.Name) == "" {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "name is required"})
+		return
+	}
+	tenant, ok := newCredentialTenant(c, payload.Tenant)
+	if !ok || !h.canGrantScopes(ctx, c, payload.Scopes) {
+		return
+	}
+
+	cl, err := CreateOAuthClient(ctx, h.db, payload.Name, payload.Scopes, tenant)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnknownScope), errors.Is(err, ErrUnknownTenant), errors.Is(err, ErrGlobalScope):
+			writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: err.Error()})
+		case errors.Is(err, ErrOAuthClientNameTaken):
+			writeError(c, http.StatusConflict, Error{Code: "CONFLICT", Message: err.Error()})
+		default:
+			internalError(ctx, c, err)
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, cl)
+}
+
+// listOAuthClients godoc
+// @Summary      List OAuth clients
+// @Description  Returns registered clients (never their secrets), including revoked ones. Tenant-bound callers only see clients bound to their tenant.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit   query     int     false  "Maximum number of clients to return (default 50, max 500)"
+// @Param        cursor  query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200     {object}  OAuthClientPage
+// @Failure      400     {object}  Error  "Bad request"
+// @Failure      401     {object}  Error  "Unauthorized"
+// @Failure      403     {object}  Error  "Forbidden (token lacks apikeys:admin)"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /admin/oauth-clients [get]
+func (h *Handler) listOAuthClients(ctx context.Context, c *app.RequestContext) {
+	limit := defaultEventLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxEventLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+	offset := 0
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.Atoi(cursorStr)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		offset = v
+	}
+
+	items, err := ListOAuthClients(ctx, h.db, boundTenant(c), limit+1, offset)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	var nextCursor *string
+	if len(items) > limit {
+		items = items[:limit]
+		next := strconv.Itoa(offset + limit)
+		nextCursor = &next
+	}
+	c.JSON(http.StatusOK, OAuthClientPage{Items: items, NextCursor: nextCursor})
+}
+
+// revokeOAuthClient godoc
+// @Summary      Revoke an OAuth client
+// @Description  Disables the client; access tokens it was issued are rejected from then on.
+// @Tags         Admin
+// @Security     BearerAuth
+// @Param        clientId  path  string  true  "Client ID or name"
+// @Success      204  "Client revoked"
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks apikeys:admin)"
+// @Failure      404  {object}  Error  "Client not found, already revoked or bound to another tenant"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Router       /admin/oauth-clients/{clientId} [delete]
+func (h *Handler) revokeOAuthClient(ctx context.Context, c *app.RequestContext) {
+	if err := RevokeOAuthClient(ctx, h.db, c.Param("clientId"), boundTenant(c)); err != nil {
+		if errors.Is(err, ErrOAuthClientNotFound) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: err.Error()})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
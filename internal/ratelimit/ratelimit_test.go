@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(60, 2)
+	l.now = func() time.Time { return now }
+
+	allow := func(t *testing.T, key string, want bool) time.Duration {
+		t.Helper()
+		ok, wait := l.Allow(key)
+		if ok != want {
+			t.Fatalf("Allow(%q) = %v, want %v", key, ok, want)
+		}
+		return wait
+	}
+
+	allow(t, "a", true)
+	allow(t, "a", true)
+	if wait := allow(t, "a", false); wait != time.Second {
+		t.Fatalf("wait = %s, want 1s", wait)
+	}
+	// Buckets are independent per key.
+	allow(t, "b", true)
+
+	now = now.Add(500 * time.Millisecond)
+	if wait := allow(t, "a", false); wait != 500*time.Millisecond {
+		t.Fatalf("wait after half a refill = %s, want 500ms", wait)
+	}
+	now = now.Add(500 * time.Millisecond)
+	allow(t, "a", true)
+
+	// Refills never exceed the burst.
+	now = now.Add(time.Hour)
+	allow(t, "a", true)
+	allow(t, "a", true)
+	allow(t, "a", false)
+}
+
+func TestLimiterSweepsFullBuckets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(60, 1)
+	l.now = func() time.Time { return now }
+	l.Allow("a")
+	l.Allow("b")
+
+	now = now.Add(sweepInterval)
+	l.Allow("c")
+	if _, ok := l.buckets["a"]; ok || len(l.buckets) != 1 {
+		t.Fatalf("buckets after sweep = %v, want only c", l.buckets)
+	}
+}
+
+func TestDisabledLimiterAllowsEverything(t *testing.T) {
+	for _, l := range []*Limiter{New(0, 5), New(10, 0)} {
+		if l != nil {
+			t.Fatalf("New with a non-positive setting = %v, want nil", l)
+		}
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatal("nil limiter rejected a request")
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"Robin-Camp/internal/authz"
+	"Robin-Camp/internal/jwt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// RaterJWTAudience is the aud claim a rater JWT must carry. Bearer and OAuth tokens never name it, so they are
+// not mistaken for rater identities even if signed with the same secret.
+const RaterJWTAudience = "robin-camp:raters"
+
+const (
+	raterIDKey = "raterId"
+
+	// issuedRaterPrefix starts every ID created by POST /raters.
+	issuedRaterPrefix = "r_"
+)
+
+var errInvalidRaterToken = errors.New("invalid rater token")
+
+// WithRaterAuth enables verifiable rater identities. Tokens are signed with secret; mode is one of
+// authz.RaterAuthPlain, authz.RaterAuthCompat or authz.RaterAuthStrict and defaults to compat when a secret is set.
+func WithRaterAuth(secret []byte, mode string) HandlerOption {
+	return func(h *Handler) {
+		if len(secret) == 0 {
+			h.raterAuthMode = authz.RaterAuthPlain
+			return
+		}
+		h.raterSecret = secret
+		h.raterVerifier = jwt.NewVerifier(jwt.WithHMACSecret(secret), jwt.WithAudience(RaterJWTAudience))
+		switch mode {
+		case authz.RaterAuthPlain, authz.RaterAuthStrict:
+			h.raterAuthMode = mode
+		default:
+			h.raterAuthMode = authz.RaterAuthCompat
+		}
+	}
+}
+
+// signRaterID returns a token of the form "<raterId>.<base64url HMAC-SHA256>".
+func (h *Handler) signRaterID(raterID string) string {
+	mac := hmac.New(sha256.New, h.raterSecret)
+	mac.Write([]byte("rater:" + raterID))
+	return raterID + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// resolveRater maps the raw X-Rater-Id header to a rater ID according to the configured mode.
+// Rater IDs are public (reviews list them), so a plain ID is never accepted for a rater that holds a token.
+func (h *Handler) resolveRater(ctx context.Context, raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errInvalidRaterToken
+	}
+	if h.raterAuthMode == authz.RaterAuthPlain || h.raterAuthMode == "" {
+		return raw, nil
+	}
+
+	if jwt.LooksLikeJWT(raw) {
+		claims, err := h.raterVerifier.Verify(raw)
+		if err != nil || claims.Subject == "" {
+			return "", errInvalidRaterToken
+		}
+		return claims.Subject, nil
+	}
+
+	if i := strings.LastIndexByte(raw, '.'); i > 0 {
+		if id := raw[:i]; hmac.Equal([]byte(h.signRaterID(id)), []byte(raw)) {
+			return id, nil
+		}
+	}
+
+	if h.raterAuthMode == authz.RaterAuthCompat && !strings.HasPrefix(raw, issuedRaterPrefix) {
+		tokenIssued, err := raterTokenIssued(ctx, h.db, raw)
+		if err != nil {
+			return "", err
+		}
+		if !tokenIssued {
+			return raw, nil
+		}
+	}
+	return "", errInvalidRaterToken
+}
+
+// raterTokenIssued reports whether issueRaterToken has signed a token for raterID.
+func raterTokenIssued(ctx context.Context, db *sql.DB, raterID string) (bool, error) {
+	var issued bool
+	err := db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM rater_tokens WHERE rater_id = ?)`, raterID).Scan(&issued)
+	if err != nil {
+		return false, fmt.Errorf("look up rater token: %w", err)
+	}
+	return issued, nil
+}
+
+// currentRater returns the identity established by requireRater.
+func currentRater(c *app.RequestContext) string {
+	return c.GetString(raterIDKey)
+}
+
+func newRaterID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return issuedRaterPrefix + hex.EncodeToString(b), nil
+}
+
+// createRater godoc
+// @Summary      Register a rater identity
+// @Description  Issues a new random rater ID together with a signed token. Send the token as X-Rater-Id on rating endpoints; the server derives the rater ID from it. Registrations are rate limited per client IP.
+// @Tags         Raters
+// @Produce      json
+// @Success      201  {object}  RaterToken
+// @Failure      429  {object}  Error  "Too many registrations from this client IP"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Failure      501  {object}  Error  "Rater tokens are not configured"
+// @Router       /raters [post]
+func (h *Handler) createRater(ctx context.Context, c *app.RequestContext) {
+	if len(h.raterSecret) == 0 {
+		writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: "rater tokens are not configured"})
+		return
+	}
+	id, err := newRaterID()
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, RaterToken{RaterID: id, Token: h.signRaterID(id)})
+}
+
+// issueRaterToken godoc
+// @Summary      Issue a token for an existing rater ID
+// @Description  Signs a token for a rater ID that predates verifiable identities, so existing raters can migrate off plain X-Rater-Id values. From then on compat mode rejects the plain ID.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        raterId  path      string  true  "Rater identifier"
+// @Success      200      {object}  RaterToken
+// @Failure      401      {object}  Error  "Unauthorized"
+// @Failure      403      {object}  Error  "Forbidden (token lacks ratings:admin)"
+// @Failure      501      {object}  Error  "Rater tokens are not configured"
+// @Router       /admin/raters/{raterId}/token [post]
+func (h *Handler) issueRaterToken(ctx context.Context, c *app.RequestContext) {
+	if len(h.raterSecret) == 0 {
+		writeError(c, http.StatusNotImplemented, Error{Code: "NOT_CONFIGURED", Message: "rater tokens are not configured"})
+		return
+	}
+	id := c.Param("raterId")
+	if _, err := h.db.ExecContext(ctx, `INSERT OR IGNORE INTO rater_tokens (rater_id) VALUES (?)`, id); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RaterToken{RaterID: id, Token: h.signRaterID(id)})
+}
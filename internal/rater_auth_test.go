@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"Robin-Camp/internal/authz"
+	"Robin-Camp/internal/jwt"
+)
+
+func TestRaterIdentities(t *testing.T) {
+	secret := []byte("rater-secret")
+	_, engine := newTestServer(t, testAuthToken, WithRaterAuth(secret, authz.RaterAuthCompat))
+	createTestMovie(t, engine, "Heat", "")
+
+	w := do(engine, "POST", "/raters", "")
+	expectStatus(t, w, http.StatusCreated)
+	var registered RaterToken
+	decode(t, w, &registered)
+
+	rate := func(raterHeader string) int {
+		return do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", raterHeader).Code
+	}
+
+	raterJWT := func(claims jwt.Claims) string {
+		claims.ExpiresAt = time.Now().Add(time.Hour).Unix()
+		token, err := jwt.SignHS256(claims, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"signed token", registered.Token, http.StatusCreated},
+		{"JWT for raters", raterJWT(jwt.Claims{Subject: "jwt-1", Audience: jwt.Audience{RaterJWTAudience}}), http.StatusCreated},
+		{"JWT without the rater audience", raterJWT(jwt.Claims{Subject: "jwt-2"}), http.StatusUnauthorized},
+		{"JWT for another audience", raterJWT(jwt.Claims{Subject: "jwt-3", Audience: jwt.Audience{"robin-camp"}}), http.StatusUnauthorized},
+		{"issued ID without its token", registered.RaterID, http.StatusUnauthorized},
+		{"token with a forged signature", registered.RaterID + ".AAAA", http.StatusUnauthorized},
+		{"legacy plain ID", "legacy-1", http.StatusCreated},
+		{"plain ID in the issued namespace", "r_0123456789abcdef", http.StatusUnauthorized},
+		{"missing header", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rate(tt.header); got != tt.want {
+				t.Fatalf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("legacy ID stops working once a token is issued", func(t *testing.T) {
+		w := do(engine, "POST", "/admin/raters/legacy-1/token", "", bearer(testAuthToken)...)
+		expectStatus(t, w, http.StatusOK)
+		var issued RaterToken
+		decode(t, w, &issued)
+
+		if got := rate("legacy-1"); got != http.StatusUnauthorized {
+			t.Fatalf("plain ID: status = %d, want 401", got)
+		}
+		if got := rate(issued.Token); got != http.StatusOK {
+			t.Fatalf("issued token: status = %d, want 200", got)
+		}
+	})
+}
+
+func TestRaterIdentitiesStrict(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken, WithRaterAuth([]byte("rater-secret"), authz.RaterAuthStrict))
+	createTestMovie(t, engine, "Heat", "")
+
+	w := do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "legacy-1")
+	expectStatus(t, w, http.StatusUnauthorized)
+}
@@ -0,0 +1,241 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	ratingEventCreate = "create"
+	ratingEventUpdate = "update"
+	ratingEventDelete = "delete"
+
+	defaultEventLimit = 50
+	maxEventLimit     = 500
+)
+
+// errRatingNotFound signals that the rater has no rating for the movie.
+var errRatingNotFound = errors.New("rating not found")
+
+// insertRatingEvent appends a row to the rating_events audit trail.
+func insertRatingEvent(ctx context.Context, tx *sql.Tx, movieID, raterID, action string, oldRating, newRating *float64, clientIP string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO rating_events (movie_id, rater_id, action, old_rating, new_rating, client_ip) VALUES (?, ?, ?, ?, ?, ?)`,
+		movieID, raterID, action, oldRating, newRating, nullIfEmpty(clientIP),
+	)
+	if err != nil {
+		return fmt.Errorf("insert rating event: %w", err)
+	}
+	return nil
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// removeRating deletes the rater's rating for the movie and appends a delete event.
+func removeRating(ctx context.Context, tx *sql.Tx, movieID, raterID, clientIP string) error {
+	var old float64
+	if err := tx.QueryRowContext(ctx, `SELECT rating FROM ratings WHERE movie_id = ? AND rater_id = ?`, movieID, raterID).Scan(&old); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errRatingNotFound
+		}
+		return fmt.Errorf("lookup rating: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ratings WHERE movie_id = ? AND rater_id = ?`, movieID, raterID); err != nil {
+		return fmt.Errorf("delete rating: %w", err)
+	}
+	return insertRatingEvent(ctx, tx, movieID, raterID, ratingEventDelete, &old, nil, clientIP)
+}
+
+// currentRatingETag returns the ETag of the rater's rating for the movie, or "" when there is none.
+func currentRatingETag(ctx context.Context, tx *sql.Tx, movieID, raterID string) (string, error) {
+	var updatedAt, title, body, language string
+	var rating float64
+	var spoiler bool
+	if err := tx.QueryRowContext(ctx, `SELECT updated_at, rating, COALESCE(review_title, ''), COALESCE(review_body, ''), review_spoiler, COALESCE(review_language, '')
+        FROM ratings WHERE movie_id = ? AND rater_id = ?`, movieID, raterID).Scan(&updatedAt, &rating, &title, &body, &spoiler, &language); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", fmt.Errorf("lookup rating: %w", err)
+	}
+	content := strings.Join([]string{strconv.FormatFloat(rating, 'f', -1, 64), title, body, strconv.FormatBool(spoiler), language}, "\x00")
+	return ratingETag(movieID, raterID, updatedAt, content), nil
+}
+
+// deleteRating godoc
+// @Summary      Delete the caller's rating for a movie
+// @Description  Removes the rating submitted by the X-Rater-Id caller. The deletion is recorded in the rating event history.
+// @Tags         Ratings
+// @Produce      json
+// @Security     RaterId
+// @Param        title       path      string  true  "Movie title"
+// @Param        X-Rater-Id  header    string  true  "Rater identifier, signed rater token or rater JWT"
+// @Param        If-Match    header    string  false  "ETag of the caller's current rating; the delete fails with 412 when it has changed"
+// @Success      204         "Rating deleted"
+// @Failure      401         {object}  Error  "Unauthorized (missing or invalid X-Rater-Id)"
+// @Failure      404         {object}  Error  "Movie or rating not found"
+// @Failure      412         {object}  Error  "If-Match does not match the caller's current rating"
+// @Failure      500         {object}  Error  "Internal server error"
+// @Router       /movies/{title}/ratings [delete]
+func (h *Handler) deleteRating(ctx context.Context, c *app.RequestContext) {
+	title := strings.TrimSpace(c.Param("title"))
+	raterID := currentRater(c)
+
+	var movieID string
+	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, currentTenant(c), title).Scan(&movieID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		if len(c.GetHeader("If-Match")) > 0 {
+			current, err := currentRatingETag(ctx, tx, movieID, raterID)
+			if err != nil {
+				return err
+			}
+			if current == "" {
+				return errRatingNotFound
+			}
+			if err := checkIfMatch(c, current); err != nil {
+				return err
+			}
+		}
+		return removeRating(ctx, tx, movieID, raterID, c.ClientIP())
+	}); err != nil {
+		if errors.Is(err, errRatingNotFound) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "rating not found"})
+			return
+		}
+		if errors.Is(err, errPreconditionFailed) {
+			preconditionFailed(c)
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listRatingEvents godoc
+// @Summary      Page through rating history
+// @Description  Returns the append-only rating event log (create, update, delete) for a movie and/or a rater, oldest first. At least one of title, movieId or raterId is required.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        title    query     string  false  "Movie title"
+// @Param        movieId  query     string  false  "Movie ID (also matches events of deleted movies)"
+// @Param        raterId  query     string  false  "Rater identifier"
+// @Param        limit    query     int     false  "Maximum number of events to return (default 50, max 500)"
+// @Param        cursor   query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200      {object}  RatingEventPage
+// @Failure      400      {object}  Error  "Bad request"
+// @Failure      401      {object}  Error  "Unauthorized"
+// @Failure      403      {object}  Error  "Forbidden (token lacks ratings:admin)"
+// @Failure      404      {object}  Error  "Movie not found"
+// @Failure      500      {object}  Error  "Internal server error"
+// @Router       /admin/rating-events [get]
+func (h *Handler) listRatingEvents(ctx context.Context, c *app.RequestContext) {
+	title := c.Query("title")
+	movieID := c.Query("movieId")
+	raterID := c.Query("raterId")
+	if title == "" && movieID == "" && raterID == "" {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "one of title, movieId or raterId is required"})
+		return
+	}
+
+	limit := defaultEventLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxEventLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+
+	var cursor int64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.ParseInt(cursorStr, 10, 64)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		cursor = v
+	}
+
+	if title != "" && movieID == "" {
+		if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, currentTenant(c), title).Scan(&movieID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+				return
+			}
+			internalError(ctx, c, err)
+			return
+		}
+	}
+
+	events, nextCursor, err := listRatingEventsFromDB(ctx, h.db, currentTenant(c), movieID, raterID, limit, cursor)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RatingEventPage{Items: events, NextCursor: nextCursor})
+}
+
+// listRatingEventsFromDB pages through the tenant's rating events. Events are attributed to a tenant through their
+// movie, so those left behind by a deleted movie are no longer listed.
+func listRatingEventsFromDB(ctx context.Context, db *sql.DB, tenant, movieID, raterID string, limit int, cursor int64) ([]RatingEvent, *string, error) {
+	where := []string{"m.tenant_id = ?", "e.id > ?"}
+	args := []any{tenant, cursor}
+	if movieID != "" {
+		where = append(where, "e.movie_id = ?")
+		args = append(args, movieID)
+	}
+	if raterID != "" {
+		where = append(where, "e.rater_id = ?")
+		args = append(args, raterID)
+	}
+	args = append(args, limit+1)
+
+	rows, err := db.QueryContext(ctx, `SELECT e.id, e.movie_id, m.title, e.rater_id, e.action, e.old_rating, e.new_rating, e.client_ip, e.created_at FROM rating_events e JOIN movies m ON m.id = e.movie_id WHERE `+strings.Join(where, " AND ")+` ORDER BY e.id LIMIT ?`, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	res := []RatingEvent{}
+	for rows.Next() {
+		var e RatingEvent
+		if err := rows.Scan(&e.ID, &e.MovieID, &e.MovieTitle, &e.RaterID, &e.Action, &e.OldRating, &e.NewRating, &e.ClientIP, &e.CreatedAt); err != nil {
+			return nil, nil, err
+		}
+		res = append(res, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var nextCursor *string
+	if len(res) > limit {
+		res = res[:limit]
+		next := strconv.FormatInt(res[limit-1].ID, 10)
+		nextCursor = &next
+	}
+	return res, nextCursor, nil
+}
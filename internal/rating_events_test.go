@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRatingEvents(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	createTestMovie(t, engine, "Heat", "")
+	createTestMovie(t, engine, "Alien", "")
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "u1"), http.StatusCreated)
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":2.5}`, "X-Rater-Id", "u1"), http.StatusOK)
+	expectStatus(t, do(engine, "POST", "/movies/Alien/ratings", `{"rating":5}`, "X-Rater-Id", "u1"), http.StatusCreated)
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":3}`, "X-Rater-Id", "u2"), http.StatusCreated)
+	expectStatus(t, do(engine, "DELETE", "/movies/Heat/ratings", "", "X-Rater-Id", "u1"), http.StatusNoContent)
+
+	events := func(t *testing.T, query string) RatingEventPage {
+		t.Helper()
+		w := do(engine, "GET", "/admin/rating-events?"+query, "", bearer(testAuthToken)...)
+		expectStatus(t, w, http.StatusOK)
+		var page RatingEventPage
+		decode(t, w, &page)
+		return page
+	}
+	rating := func(v float64) *float64 { return &v }
+	same := func(a, b *float64) bool { return (a == nil) == (b == nil) && (a == nil || *a == *b) }
+
+	t.Run("history of one rater on one movie", func(t *testing.T) {
+		page := events(t, "title=Heat&raterId=u1")
+		want := []struct {
+			action   string
+			old, new *float64
+		}{
+			{ratingEventCreate, nil, rating(4)},
+			{ratingEventUpdate, rating(4), rating(2.5)},
+			{ratingEventDelete, rating(2.5), nil},
+		}
+		if len(page.Items) != len(want) {
+			t.Fatalf("events = %+v, want %d", page.Items, len(want))
+		}
+		for i, w := range want {
+			e := page.Items[i]
+			if e.Action != w.action || !same(e.OldRating, w.old) || !same(e.NewRating, w.new) {
+				t.Errorf("event %d = %s %v -> %v, want %s %v -> %v", i, e.Action, e.OldRating, e.NewRating, w.action, w.old, w.new)
+			}
+		}
+	})
+
+	t.Run("pages follow the cursor", func(t *testing.T) {
+		var seen int
+		query := "raterId=u1&limit=2"
+		for range 3 {
+			page := events(t, query)
+			seen += len(page.Items)
+			if page.NextCursor == nil {
+				break
+			}
+			query = "raterId=u1&limit=2&cursor=" + *page.NextCursor
+		}
+		if seen != 4 {
+			t.Fatalf("paged through %d events, want 4", seen)
+		}
+	})
+
+	t.Run("a filter is required", func(t *testing.T) {
+		expectStatus(t, do(engine, "GET", "/admin/rating-events", "", bearer(testAuthToken)...), http.StatusBadRequest)
+	})
+}
@@ -0,0 +1,387 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"Robin-Camp/internal/authz"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// Subject kinds a role can be assigned to.
+const (
+	subjectPrincipal = "principal"
+	subjectRater     = "rater"
+)
+
+// rolePermissions is the role-to-permission matrix.
+var rolePermissions = map[string][]string{
+	authz.RoleViewer:    {ScopeMoviesRead, ScopeRatingsRead},
+	authz.RoleRater:     {ScopeMoviesRead, ScopeRatingsRead, ScopeRatingsWrite},
+	authz.RoleEditor:    {ScopeMoviesRead, ScopeRatingsRead, ScopeMoviesWrite, ScopeBoxOfficeAdmin},
+	authz.RoleModerator: {ScopeMoviesRead, ScopeRatingsRead, ScopeRatingsWrite, ScopeRatingsAdmin},
+	authz.RoleAdmin:     KnownScopes,
+}
+
+// How a route authenticates its caller.
+const (
+	// authPublic routes are open to anonymous callers whose role grants the permission; otherwise a bearer principal is required.
+	authPublic = "public"
+	// authRater routes identify the caller through X-Rater-Id.
+	authRater = "rater"
+	// authBearer routes require an Authorization: Bearer credential.
+	authBearer = "bearer"
+)
+
+// routePolicy binds a route to its authentication source and required permission.
+type routePolicy struct {
+	Method     string
+	Path       string
+	Auth       string
+	Permission string
+	Handler    app.HandlerFunc
+}
+
+// routePolicies is the declarative route-to-permission table that RegisterRoutes installs.
+func (h *Handler) routePolicies() []routePolicy {
+	return []routePolicy{
+		{http.MethodGet, "/movies", authPublic, ScopeMoviesRead, h.listMovies},
+		{http.MethodGet, "/movies/trending", authPublic, ScopeMoviesRead, h.getTrending},
+		{http.MethodGet, "/movies/:title", authPublic, ScopeMoviesRead, h.getMovieByTitle},
+		{http.MethodPost, "/movies", authBearer, ScopeMoviesWrite, h.idempotent(h.createMovie)},
+		{http.MethodPost, "/movies:batch", authBearer, ScopeMoviesWrite, h.importMovies},
+		{http.MethodGet, "/movies/:title/rating", authPublic, ScopeRatingsRead, h.getRatingAggregate},
+		{http.MethodPost, "/movies/:title/ratings", authRater, ScopeRatingsWrite, h.idempotent(h.rateLimitRatings(h.submitRating))},
+		{http.MethodDelete, "/movies/:title/ratings", authRater, ScopeRatingsWrite, h.deleteRating},
+		{http.MethodGet, "/movies/:title/reviews", authPublic, ScopeRatingsRead, h.listReviews},
+		{http.MethodPost, "/movies/:title/reviews/:raterId/helpful", authRater, ScopeRatingsWrite, h.rateLimitRatings(h.markReviewHelpful)},
+		{http.MethodPost, "/movies/:title/ratings/:raterId/reports", authRater, ScopeRatingsWrite, h.rateLimitRatings(h.reportRating)},
+		{http.MethodPost, "/movies/:title/boxoffice/refresh", authBearer, ScopeBoxOfficeAdmin, h.refreshBoxOffice},
+
+		{http.MethodGet, "/export/movies", authBearer, ScopeMoviesRead, h.exportMovies},
+		{http.MethodGet, "/export/ratings", authBearer, ScopeRatingsAdmin, h.exportRatings},
+
+		{http.MethodPost, "/raters", authPublic, "", h.rateLimitRegistrations(h.createRater)},
+		// OAuth endpoints authenticate the client themselves.
+		{http.MethodPost, "/oauth/token", authPublic, "", h.issueOAuthToken},
+		{http.MethodPost, "/oauth/introspect", authPublic, "", h.introspectOAuthToken},
+		{http.MethodPost, "/oauth/revoke", authPublic, "", h.revokeOAuthToken},
+
+		{http.MethodPost, "/admin/raters/:raterId/token", authBearer, ScopeRatingsAdmin, h.issueRaterToken},
+		{http.MethodGet, "/admin/rating-events", authBearer, ScopeRatingsAdmin, h.listRatingEvents},
+		{http.MethodGet, "/admin/moderation/queue", authBearer, ScopeRatingsAdmin, h.listModerationQueue},
+		{http.MethodPost, "/admin/moderation/items/:movieId/:raterId/:decision", authBearer, ScopeRatingsAdmin, h.decideModeration},
+		{http.MethodGet, "/admin/flagged-raters", authBearer, ScopeRatingsAdmin, h.listFlaggedRaters},
+		{http.MethodDelete, "/admin/flagged-raters/:raterId", authBearer, ScopeRatingsAdmin, h.unflagRater},
+		{http.MethodPost, "/admin/api-keys", authBearer, ScopeAPIKeysAdmin, h.createAPIKey},
+		{http.MethodGet, "/admin/api-keys", authBearer, ScopeAPIKeysAdmin, h.listAPIKeys},
+		{http.MethodPost, "/admin/api-keys/:id/rotate", authBearer, ScopeAPIKeysAdmin, h.rotateAPIKey},
+		{http.MethodDelete, "/admin/api-keys/:id", authBearer, ScopeAPIKeysAdmin, h.revokeAPIKey},
+		{http.MethodPost, "/admin/oauth-clients", authBearer, ScopeAPIKeysAdmin, h.createOAuthClient},
+		{http.MethodGet, "/admin/oauth-clients", authBearer, ScopeAPIKeysAdmin, h.listOAuthClients},
+		{http.MethodDelete, "/admin/oauth-clients/:clientId", authBearer, ScopeAPIKeysAdmin, h.revokeOAuthClient},
+		{http.MethodGet, "/admin/roles", authBearer, ScopeRolesAdmin, h.listRoles},
+		{http.MethodGet, "/admin/policy", authBearer, ScopeRolesAdmin, h.listRoutePolicies},
+		{http.MethodGet, "/admin/role-assignments", authBearer, ScopeRolesAdmin, h.listRoleAssignments},
+		{http.MethodPut, "/admin/role-assignments/:kind/:subject/:role", authBearer, ScopeRolesAdmin, h.assignRole},
+		{http.MethodDelete, "/admin/role-assignments/:kind/:subject/:role", authBearer, ScopeRolesAdmin, h.unassignRole},
+		{http.MethodGet, "/admin/tenants", authBearer, ScopeTenantsAdmin, h.listTenants},
+		{http.MethodPut, "/admin/tenants/:tenantId", authBearer, ScopeTenantsAdmin, h.putTenant},
+		{http.MethodPost, "/admin/backups", authBearer, ScopeBackupsAdmin, h.createBackup},
+		{http.MethodGet, "/admin/backups", authBearer, ScopeBackupsAdmin, h.listBackups},
+
+		{http.MethodGet, "/metrics", authBearer, ScopeMetricsRead, h.metrics},
+	}
+}
+
+// WithRoleDefaults sets the role granted to anonymous callers and to raters without an explicit assignment.
+// Empty values keep the defaults (viewer and rater); authz.RoleNone grants nothing.
+func WithRoleDefaults(anonymousRole, raterRole string) HandlerOption {
+	return func(h *Handler) {
+		if anonymousRole != "" {
+			h.anonymousRole = anonymousRole
+		}
+		if raterRole != "" {
+			h.defaultRaterRole = raterRole
+		}
+	}
+}
+
+// authorize wraps the policy handler with the route's body size limit, tenant resolution and the authentication and
+// permission check the policy declares.
+func (h *Handler) authorize(p routePolicy) app.HandlerFunc {
+	var next app.HandlerFunc
+	switch p.Auth {
+	case authBearer:
+		next = h.requireScope(p.Permission, p.Handler)
+		if h.adminClientCerts && strings.HasPrefix(p.Path, "/admin/") {
+			next = requireClientCert(next)
+		}
+	case authRater:
+		next = h.requireRater(h.requireRaterPermission(p.Permission, p.Handler))
+	default:
+		if p.Permission == "" || roleAllows(h.anonymousRole, p.Permission) {
+			next = p.Handler
+		} else {
+			next = h.requireScope(p.Permission, p.Handler)
+		}
+	}
+	return limitBody(h.bodyLimit(p), h.resolveTenant(next))
+}
+
+// requireRaterPermission checks the rater's roles, falling back to the default rater role when none are assigned.
+func (h *Handler) requireRaterPermission(perm string, next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		roles, err := assignedRoles(ctx, h.db, currentTenant(c), subjectRater, currentRater(c))
+		if err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		if len(roles) == 0 {
+			roles = []string{h.defaultRaterRole}
+		}
+		if !slices.ContainsFunc(roles, func(r string) bool { return roleAllows(r, perm) }) {
+			writeError(c, http.StatusForbidden, Error{Code: "FORBIDDEN", Message: "rater lacks permission " + perm})
+			return
+		}
+		next(ctx, c)
+	}
+}
+
+func roleAllows(role, perm string) bool {
+	return slices.Contains(rolePermissions[role], perm)
+}
+
+// subjectHasPermission reports whether any role assigned to the subject in the tenant grants perm.
+func (h *Handler) subjectHasPermission(ctx context.Context, tenant, kind, subject, perm string) bool {
+	if subject == "" {
+		return false
+	}
+	roles, err := assignedRoles(ctx, h.db, tenant, kind, subject)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(roles, func(r string) bool { return roleAllows(r, perm) })
+}
+
+func assignedRoles(ctx context.Context, db *sql.DB, tenant, kind, subject string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT role FROM role_assignments WHERE tenant_id = ? AND subject_kind = ? AND subject = ?`, tenant, kind, subject)
+	if err != nil {
+		return nil, fmt.Errorf("load roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var r string
+		if err := rows.Scan(&r); err != nil {
+			return nil, err
+		}
+		roles = append(roles, r)
+	}
+	return roles, rows.Err()
+}
+
+// listRoles godoc
+// @Summary      List roles
+// @Description  Returns every assignable role with the permissions it grants.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Role
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks roles:admin or is bound to a tenant)"
+// @Router       /admin/roles [get]
+func (h *Handler) listRoles(ctx context.Context, c *app.RequestContext) {
+	roles := make([]Role, 0, len(rolePermissions))
+	for _, name := range authz.Roles {
+		roles = append(roles, Role{Name: name, Permissions: rolePermissions[name]})
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// listRoutePolicies godoc
+// @Summary      Show the route permission matrix
+// @Description  Returns each route with its authentication source (public, rater or bearer) and the permission it requires.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   RoutePermission
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks roles:admin or is bound to a tenant)"
+// @Router       /admin/policy [get]
+func (h *Handler) listRoutePolicies(ctx context.Context, c *app.RequestContext) {
+	policies := h.routePolicies()
+	res := make([]RoutePermission, 0, len(policies))
+	for _, p := range policies {
+		res = append(res, RoutePermission{Method: p.Method, Path: p.Path, Auth: p.Auth, Permission: p.Permission})
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// listRoleAssignments godoc
+// @Summary      List role assignments
+// @Description  Returns role assignments in the current tenant, optionally filtered by subject kind and subject.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        kind     query     string  false  "principal (API key name, JWT sub or OAuth client ID) or rater"
+// @Param        subject  query     string  false  "Subject identifier"
+// @Param        limit    query     int     false  "Maximum number of items to return (default 50, max 500)"
+// @Param        cursor   query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200      {object}  RoleAssignmentPage
+// @Failure      400      {object}  Error  "Bad request"
+// @Failure      401      {object}  Error  "Unauthorized"
+// @Failure      403      {object}  Error  "Forbidden (token lacks roles:admin or is bound to a tenant)"
+// @Failure      500      {object}  Error  "Internal server error"
+// @Router       /admin/role-assignments [get]
+func (h *Handler) listRoleAssignments(ctx context.Context, c *app.RequestContext) {
+	kind := c.Query("kind")
+	subject := c.Query("subject")
+	if kind != "" && kind != subjectPrincipal && kind != subjectRater {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "kind must be principal or rater"})
+		return
+	}
+	limit := defaultEventLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxEventLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+	offset := 0
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.Atoi(cursorStr)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		offset = v
+	}
+
+	rows, err := h.db.QueryContext(ctx, `SELECT subject_kind, subject, role, created_at FROM role_assignments WHERE tenant_id = ? AND (? = '' OR subject_kind = ?) AND (? = '' OR subject = ?) ORDER BY subject_kind, subject, role LIMIT ? OFFSET ?`,
+		currentTenant(c), kind, kind, subject, subject, limit+1, offset,
+	)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	defer rows.Close()
+
+	items := []RoleAssignment{}
+	for rows.Next() {
+		var a RoleAssignment
+		if err := rows.Scan(&a.SubjectKind, &a.Subject, &a.Role, &a.CreatedAt); err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		items = append(items, a)
+	}
+	if err := rows.Err(); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	var nextCursor *string
+	if len(items) > limit {
+		items = items[:limit]
+		next := strconv.Itoa(offset + limit)
+		nextCursor = &next
+	}
+	c.JSON(http.StatusOK, RoleAssignmentPage{Items: items, NextCursor: nextCursor})
+}
+
+var errUnknownRole = errors.New("unknown role")
+
+func parseAssignment(c *app.RequestContext) (kind, subject, role string, err error) {
+	kind, subject, role = c.Param("kind"), c.Param("subject"), c.Param("role")
+	if kind != subjectPrincipal && kind != subjectRater {
+		return "", "", "", errors.New("kind must be principal or rater")
+	}
+	if _, ok := rolePermissions[role]; !ok {
+		return "", "", "", fmt.Errorf("%w: %s", errUnknownRole, role)
+	}
+	return kind, subject, role, nil
+}
+
+// assignRole godoc
+// @Summary      Assign a role
+// @Description  Grants a role in the current tenant to a bearer principal (API key name, JWT sub or OAuth client ID) or to a rater. Assigning any role to a rater replaces the default rater role for them in that tenant.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        kind     path      string  true  "principal or rater"
+// @Param        subject  path      string  true  "Subject identifier"
+// @Param        role     path      string  true  "viewer, rater, editor, moderator or admin"
+// @Success      200      {object}  RoleAssignment  "Already assigned"
+// @Success      201      {object}  RoleAssignment  "Assigned"
+// @Failure      400      {object}  Error  "Unknown kind or role"
+// @Failure      401      {object}  Error  "Unauthorized"
+// @Failure      403      {object}  Error  "Forbidden (token lacks roles:admin or is bound to a tenant)"
+// @Failure      500      {object}  Error  "Internal server error"
+// @Router       /admin/role-assignments/{kind}/{subject}/{role} [put]
+func (h *Handler) assignRole(ctx context.Context, c *app.RequestContext) {
+	kind, subject, role, err := parseAssignment(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+
+	res, err := h.db.ExecContext(ctx, `INSERT OR IGNORE INTO role_assignments (tenant_id, subject_kind, subject, role) VALUES (?, ?, ?, ?)`, currentTenant(c), kind, subject, role)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	status := http.StatusOK
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		status = http.StatusCreated
+	}
+
+	a := RoleAssignment{SubjectKind: kind, Subject: subject, Role: role}
+	if err := h.db.QueryRowContext(ctx, `SELECT created_at FROM role_assignments WHERE tenant_id = ? AND subject_kind = ? AND subject = ? AND role = ?`, currentTenant(c), kind, subject, role).Scan(&a.CreatedAt); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(status, a)
+}
+
+// unassignRole godoc
+// @Summary      Remove a role assignment
+// @Tags         Admin
+// @Security     BearerAuth
+// @Param        kind     path  string  true  "principal or rater"
+// @Param        subject  path  string  true  "Subject identifier"
+// @Param        role     path  string  true  "Role name"
+// @Success      204      "Assignment removed"
+// @Failure      400      {object}  Error  "Unknown kind or role"
+// @Failure      401      {object}  Error  "Unauthorized"
+// @Failure      403      {object}  Error  "Forbidden (token lacks roles:admin or is bound to a tenant)"
+// @Failure      404      {object}  Error  "Assignment not found"
+// @Failure      500      {object}  Error  "Internal server error"
+// @Router       /admin/role-assignments/{kind}/{subject}/{role} [delete]
+func (h *Handler) unassignRole(ctx context.Context, c *app.RequestContext) {
+	kind, subject, role, err := parseAssignment(c)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.db.ExecContext(ctx, `DELETE FROM role_assignments WHERE tenant_id = ? AND subject_kind = ? AND subject = ? AND role = ?`, currentTenant(c), kind, subject, role)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "role assignment not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
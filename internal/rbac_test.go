@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"Robin-Camp/internal/authz"
+)
+
+func TestRoutePolicies(t *testing.T) {
+	want := []struct {
+		method, path, auth, scope string
+	}{
+		{"GET", "/movies", authPublic, ScopeMoviesRead},
+		{"GET", "/movies/trending", authPublic, ScopeMoviesRead},
+		{"GET", "/movies/:title", authPublic, ScopeMoviesRead},
+		{"POST", "/movies", authBearer, ScopeMoviesWrite},
+		{"POST", "/movies:batch", authBearer, ScopeMoviesWrite},
+		{"GET", "/movies/:title/rating", authPublic, ScopeRatingsRead},
+		{"POST", "/movies/:title/ratings", authRater, ScopeRatingsWrite},
+		{"DELETE", "/movies/:title/ratings", authRater, ScopeRatingsWrite},
+		{"GET", "/movies/:title/reviews", authPublic, ScopeRatingsRead},
+		{"POST", "/movies/:title/reviews/:raterId/helpful", authRater, ScopeRatingsWrite},
+		{"POST", "/movies/:title/ratings/:raterId/reports", authRater, ScopeRatingsWrite},
+		{"POST", "/movies/:title/boxoffice/refresh", authBearer, ScopeBoxOfficeAdmin},
+		{"GET", "/export/movies", authBearer, ScopeMoviesRead},
+		{"GET", "/export/ratings", authBearer, ScopeRatingsAdmin},
+		{"POST", "/raters", authPublic, ""},
+		{"POST", "/oauth/token", authPublic, ""},
+		{"POST", "/oauth/introspect", authPublic, ""},
+		{"POST", "/oauth/revoke", authPublic, ""},
+		{"POST", "/admin/raters/:raterId/token", authBearer, ScopeRatingsAdmin},
+		{"GET", "/admin/rating-events", authBearer, ScopeRatingsAdmin},
+		{"GET", "/admin/moderation/queue", authBearer, ScopeRatingsAdmin},
+		{"POST", "/admin/moderation/items/:movieId/:raterId/:decision", authBearer, ScopeRatingsAdmin},
+		{"GET", "/admin/flagged-raters", authBearer, ScopeRatingsAdmin},
+		{"DELETE", "/admin/flagged-raters/:raterId", authBearer, ScopeRatingsAdmin},
+		{"POST", "/admin/api-keys", authBearer, ScopeAPIKeysAdmin},
+		{"GET", "/admin/api-keys", authBearer, ScopeAPIKeysAdmin},
+		{"POST", "/admin/api-keys/:id/rotate", authBearer, ScopeAPIKeysAdmin},
+		{"DELETE", "/admin/api-keys/:id", authBearer, ScopeAPIKeysAdmin},
+		{"POST", "/admin/oauth-clients", authBearer, ScopeAPIKeysAdmin},
+		{"GET", "/admin/oauth-clients", authBearer, ScopeAPIKeysAdmin},
+		{"DELETE", "/admin/oauth-clients/:clientId", authBearer, ScopeAPIKeysAdmin},
+		{"GET", "/admin/roles", authBearer, ScopeRolesAdmin},
+		{"GET", "/admin/policy", authBearer, ScopeRolesAdmin},
+		{"GET", "/admin/role-assignments", authBearer, ScopeRolesAdmin},
+		{"PUT", "/admin/role-assignments/:kind/:subject/:role", authBearer, ScopeRolesAdmin},
+		{"DELETE", "/admin/role-assignments/:kind/:subject/:role", authBearer, ScopeRolesAdmin},
+		{"GET", "/admin/tenants", authBearer, ScopeTenantsAdmin},
+		{"PUT", "/admin/tenants/:tenantId", authBearer, ScopeTenantsAdmin},
+		{"POST", "/admin/backups", authBearer, ScopeBackupsAdmin},
+		{"GET", "/admin/backups", authBearer, ScopeBackupsAdmin},
+		{"GET", "/metrics", authBearer, ScopeMetricsRead},
+	}
+
+	policies := make(map[string]routePolicy)
+	for _, p := range (&Handler{}).routePolicies() {
+		key := p.Method + " " + p.Path
+		if _, dup := policies[key]; dup {
+			t.Errorf("%s is declared twice", key)
+		}
+		policies[key] = p
+	}
+	for _, tt := range want {
+		key := tt.method + " " + tt.path
+		t.Run(key, func(t *testing.T) {
+			p, ok := policies[key]
+			if !ok {
+				t.Fatal("route has no policy")
+			}
+			delete(policies, key)
+			if p.Auth != tt.auth || p.Permission != tt.scope {
+				t.Errorf("policy = (%s, %q), want (%s, %q)", p.Auth, p.Permission, tt.auth, tt.scope)
+			}
+			if p.Permission != "" && !slices.Contains(KnownScopes, p.Permission) {
+				t.Errorf("permission %q is not a known scope", p.Permission)
+			}
+			if strings.HasPrefix(p.Path, "/admin/") && p.Auth != authBearer {
+				t.Errorf("admin route uses %s authentication", p.Auth)
+			}
+		})
+	}
+	for key := range policies {
+		t.Errorf("%s has a policy but no expectation in this test", key)
+	}
+}
+
+func TestAdminRoutesRejectAnonymousCallers(t *testing.T) {
+	configs := []struct {
+		name      string
+		authToken string
+		opts      []HandlerOption
+	}{
+		{name: "static token", authToken: testAuthToken},
+		// API keys enabled with none stored must not leave the admin routes open.
+		{name: "api keys only", opts: []HandlerOption{WithAPIKeyAuth(true)}},
+		// Even the admin role granted to anonymous callers does not reach bearer-only routes.
+		{name: "anonymous admin role", authToken: testAuthToken, opts: []HandlerOption{WithRoleDefaults(authz.RoleAdmin, "")}},
+	}
+	for _, cfg := range configs {
+		t.Run(cfg.name, func(t *testing.T) {
+			h, engine := newTestServer(t, cfg.authToken, cfg.opts...)
+			for _, p := range h.routePolicies() {
+				if !strings.HasPrefix(p.Path, "/admin/") {
+					continue
+				}
+				path := strings.NewReplacer(":raterId", "r1", ":movieId", "m1", ":decision", "approve", ":id", "k1",
+					":clientId", "c1", ":kind", "rater", ":subject", "s1", ":role", authz.RoleViewer, ":tenantId", "acme").Replace(p.Path)
+				for _, headers := range [][]string{nil, bearer("not-a-valid-token"), bearer("rck_0011223344556677_secret")} {
+					w := do(engine, p.Method, path, "", headers...)
+					if w.Code != http.StatusUnauthorized {
+						t.Errorf("%s %s with %v: status = %d, want 401", p.Method, path, headers, w.Code)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestScopedCredentials(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken, WithAPIKeyAuth(true))
+	reader := createTestAPIKey(t, engine, "reader", "", ScopeMoviesRead)
+	admin := createTestAPIKey(t, engine, "admin", "", ScopeRatingsAdmin)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"reader cannot create movies", "POST", "/movies", reader, http.StatusForbidden},
+		{"reader cannot export ratings", "GET", "/export/ratings", reader, http.StatusForbidden},
+		{"reader cannot list api keys", "GET", "/admin/api-keys", reader, http.StatusForbidden},
+		{"ratings admin lists flagged raters", "GET", "/admin/flagged-raters", admin, http.StatusOK},
+		{"ratings admin lists rating events", "GET", "/admin/rating-events?raterId=r1", admin, http.StatusOK},
+		{"ratings admin cannot manage tenants", "GET", "/admin/tenants", admin, http.StatusForbidden},
+		{"static token reaches every route", "GET", "/admin/tenants", testAuthToken, http.StatusOK},
+		{"unknown key is rejected", "GET", "/admin/flagged-raters", "rck_0011223344556677_secret", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, do(engine, tt.method, tt.path, "", bearer(tt.token)...), tt.want)
+		})
+	}
+
+	t.Run("revoking the last key keeps authentication on", func(t *testing.T) {
+		for _, name := range []string{"reader", "admin"} {
+			expectStatus(t, do(engine, "DELETE", "/admin/api-keys/"+name, "", bearer(testAuthToken)...), http.StatusNoContent)
+		}
+		expectStatus(t, do(engine, "GET", "/admin/flagged-raters", "", bearer(admin)...), http.StatusUnauthorized)
+		expectStatus(t, do(engine, "GET", "/admin/api-keys", ""), http.StatusUnauthorized)
+	})
+}
+
+func TestCustomMethodRoute(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	body := `{"title":"Heat","genre":"Crime","releaseDate":"1995-12-15"}`
+
+	w := do(engine, "POST", "/movies:batch", body, bearer(testAuthToken)...)
+	expectStatus(t, w, http.StatusOK)
+	var report ImportReport
+	decode(t, w, &report)
+	if report.Created != 1 {
+		t.Fatalf("report = %+v, want one created", report)
+	}
+
+	for _, path := range []string{"/movies:batches", "/moviesbatch", "/movies:other"} {
+		expectStatus(t, do(engine, "POST", path, body, bearer(testAuthToken)...), http.StatusNotFound)
+	}
+}
@@ -0,0 +1,186 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	defaultReviewMaxLength = 2000
+	maxReviewTitleLength   = 200
+	defaultReviewLimit     = 20
+	maxReviewLimit         = 100
+)
+
+// languageTagPattern loosely matches BCP 47 tags such as "en", "pt-BR" or "zh-Hant-TW".
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+func (h *Handler) validateReview(r *ReviewSubmit) error {
+	r.Title = strings.TrimSpace(r.Title)
+	r.Body = strings.TrimSpace(r.Body)
+	r.Language = strings.TrimSpace(r.Language)
+
+	if r.Body == "" {
+		return errors.New("review body is required")
+	}
+	if n := utf8.RuneCountInString(r.Body); n > h.reviewMaxLength {
+		return fmt.Errorf("review body exceeds %d characters", h.reviewMaxLength)
+	}
+	if utf8.RuneCountInString(r.Title) > maxReviewTitleLength {
+		return fmt.Errorf("review title exceeds %d characters", maxReviewTitleLength)
+	}
+	if r.Language != "" && !languageTagPattern.MatchString(r.Language) {
+		return errors.New("invalid review language tag")
+	}
+	return nil
+}
+
+// listReviews godoc
+// @Summary      List reviews for a movie
+// @Description  Returns the text reviews attached to ratings of the given movie, sorted by helpfulness (default) or recency. Reviews hidden by moderation are omitted.
+// @Tags         Reviews
+// @Produce      json
+// @Param        title   path      string  true   "Movie title"
+// @Param        sort    query     string  false  "Sort order: helpful (default) or recent"
+// @Param        limit   query     int     false  "Maximum number of items to return (default 20, max 100)"
+// @Param        cursor  query     string  false  "Pagination cursor from previous page's nextCursor"
+// @Success      200     {object}  ReviewPage
+// @Failure      400     {object}  Error  "Bad request"
+// @Failure      404     {object}  Error  "Movie not found"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /movies/{title}/reviews [get]
+func (h *Handler) listReviews(ctx context.Context, c *app.RequestContext) {
+	title := strings.TrimSpace(c.Param("title"))
+
+	order := "helpful DESC, r.review_updated_at DESC, r.rater_id"
+	switch c.Query("sort") {
+	case "", "helpful":
+	case "recent":
+		order = "r.review_updated_at DESC, r.rater_id"
+	default:
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "sort must be helpful or recent"})
+		return
+	}
+
+	limit := defaultReviewLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxReviewLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+
+	// Helpful counts shift between requests, so the cursor is a plain offset rather than a keyset.
+	offset := 0
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		v, err := strconv.Atoi(cursorStr)
+		if err != nil || v < 0 {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid cursor"})
+			return
+		}
+		offset = v
+	}
+
+	var movieID string
+	if err := h.db.QueryRowContext(ctx, `SELECT id FROM movies WHERE tenant_id = ? AND title = ?`, currentTenant(c), title).Scan(&movieID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "movie not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	rows, err := h.db.QueryContext(ctx, `SELECT r.rater_id, r.rating, r.review_title, r.review_body, r.review_spoiler, r.review_language, r.review_updated_at, (SELECT COUNT(*) FROM review_votes v WHERE v.movie_id = r.movie_id AND v.rater_id = r.rater_id) AS helpful FROM ratings r WHERE r.movie_id = ? AND r.review_body IS NOT NULL AND r.`+visibleRatingClause+` ORDER BY `+order+` LIMIT ? OFFSET ?`,
+		movieID, limit+1, offset,
+	)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	defer rows.Close()
+
+	items := []Review{}
+	for rows.Next() {
+		var r Review
+		if err := rows.Scan(&r.RaterID, &r.Rating, &r.Title, &r.Body, &r.Spoiler, &r.Language, &r.UpdatedAt, &r.HelpfulCount); err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		items = append(items, r)
+	}
+	if err := rows.Err(); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	var nextCursor *string
+	if len(items) > limit {
+		items = items[:limit]
+		next := strconv.Itoa(offset + limit)
+		nextCursor = &next
+	}
+	c.JSON(http.StatusOK, ReviewPage{Items: items, NextCursor: nextCursor})
+}
+
+// markReviewHelpful godoc
+// @Summary      Mark a review as helpful
+// @Description  Records that the X-Rater-Id caller found the review helpful. Repeated votes are ignored; raters cannot vote on their own review.
+// @Tags         Reviews
+// @Produce      json
+// @Security     RaterId
+// @Param        title       path      string  true  "Movie title"
+// @Param        raterId     path      string  true  "Author of the review"
+// @Param        X-Rater-Id  header    string  true  "Unique rater identifier of the voter"
+// @Success      200         {object}  HelpfulVoteResult
+// @Failure      401         {object}  Error  "Unauthorized (missing or invalid X-Rater-Id)"
+// @Failure      404         {object}  Error  "Movie or review not found"
+// @Failure      422         {object}  Error  "Cannot vote on own review"
+// @Failure      429         {object}  Error  "Rate limit exceeded for this rater or client IP"
+// @Failure      500         {object}  Error  "Internal server error"
+// @Router       /movies/{title}/reviews/{raterId}/helpful [post]
+func (h *Handler) markReviewHelpful(ctx context.Context, c *app.RequestContext) {
+	title := strings.TrimSpace(c.Param("title"))
+	authorID := c.Param("raterId")
+	voterID := currentRater(c)
+
+	if voterID == authorID {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "cannot mark own review helpful"})
+		return
+	}
+
+	var movieID string
+	err := h.db.QueryRowContext(ctx, `SELECT r.movie_id FROM ratings r JOIN movies m ON m.id = r.movie_id WHERE m.tenant_id = ? AND m.title = ? AND r.rater_id = ? AND r.review_body IS NOT NULL AND r.`+visibleRatingClause, currentTenant(c), title, authorID).Scan(&movieID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(c, http.StatusNotFound, Error{Code: "NOT_FOUND", Message: "review not found"})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	var count int64
+	if err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO review_votes (movie_id, rater_id, voter_id) VALUES (?, ?, ?)`, movieID, authorID, voterID); err != nil {
+			return fmt.Errorf("insert review vote: %w", err)
+		}
+		return tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM review_votes WHERE movie_id = ? AND rater_id = ?`, movieID, authorID).Scan(&count)
+	}); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, HelpfulVoteResult{RaterID: authorID, HelpfulCount: count})
+}
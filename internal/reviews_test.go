@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReviews(t *testing.T) {
+	h, engine := newTestServer(t, testAuthToken, WithReviewMaxLength(20))
+	createTestMovie(t, engine, "Heat", "")
+	submit := func(rater, body string) int {
+		return do(engine, "POST", "/movies/Heat/ratings", body, "X-Rater-Id", rater).Code
+	}
+
+	t.Run("validation", func(t *testing.T) {
+		for _, body := range []string{
+			`{"rating":4,"review":{"body":"  "}}`,
+			`{"rating":4,"review":{"body":"` + strings.Repeat("x", 21) + `"}}`,
+			`{"rating":4,"review":{"body":"Fine","language":"not a tag"}}`,
+		} {
+			if got := submit("u1", body); got != http.StatusUnprocessableEntity {
+				t.Errorf("%s: status = %d, want 422", body, got)
+			}
+		}
+	})
+
+	if got := submit("u1", `{"rating":4,"review":{"title":"Tense","body":"A fine heist","language":"en"}}`); got != http.StatusCreated {
+		t.Fatalf("u1 review: status = %d", got)
+	}
+	if got := submit("u2", `{"rating":3,"review":{"body":"Too long"}}`); got != http.StatusCreated {
+		t.Fatalf("u2 review: status = %d", got)
+	}
+	// A rating without a review keeps the stored review; a rating with none is not listed.
+	if got := submit("u1", `{"rating":5}`); got != http.StatusOK {
+		t.Fatalf("u1 rating update: status = %d", got)
+	}
+	if got := submit("u3", `{"rating":2}`); got != http.StatusCreated {
+		t.Fatalf("u3 rating: status = %d", got)
+	}
+
+	vote := func(voter, author string) *HelpfulVoteResult {
+		t.Helper()
+		w := do(engine, "POST", "/movies/Heat/reviews/"+author+"/helpful", "", "X-Rater-Id", voter)
+		if w.Code != http.StatusOK {
+			return nil
+		}
+		var res HelpfulVoteResult
+		decode(t, w, &res)
+		return &res
+	}
+	vote("u3", "u2")
+	if res := vote("u3", "u2"); res == nil || res.HelpfulCount != 1 {
+		t.Fatalf("repeated vote = %+v, want a count of 1", res)
+	}
+	expectStatus(t, do(engine, "POST", "/movies/Heat/reviews/u2/helpful", "", "X-Rater-Id", "u2"), http.StatusUnprocessableEntity)
+	expectStatus(t, do(engine, "POST", "/movies/Heat/reviews/u3/helpful", "", "X-Rater-Id", "u1"), http.StatusNotFound)
+
+	list := func(t *testing.T, query string) ReviewPage {
+		t.Helper()
+		w := do(engine, "GET", "/movies/Heat/reviews"+query, "")
+		expectStatus(t, w, http.StatusOK)
+		var page ReviewPage
+		decode(t, w, &page)
+		return page
+	}
+	raters := func(page ReviewPage) string {
+		var ids []string
+		for _, r := range page.Items {
+			ids = append(ids, r.RaterID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	t.Run("sorted by helpfulness", func(t *testing.T) {
+		page := list(t, "")
+		if got := raters(page); got != "u2,u1" {
+			t.Fatalf("raters = %s, want u2,u1", got)
+		}
+		if r := page.Items[1]; r.Rating != 5 || r.Body != "A fine heist" || r.Title == nil || *r.Title != "Tense" {
+			t.Fatalf("u1 review = %+v, want the stored review with the updated rating", r)
+		}
+	})
+	t.Run("sorted by recency", func(t *testing.T) {
+		// Both reviews may carry the same millisecond, so u2's is backdated to make the order deterministic.
+		if _, err := h.db.ExecContext(context.Background(), `UPDATE ratings SET review_updated_at = '2020-01-01T00:00:00.000Z' WHERE rater_id = 'u2'`); err != nil {
+			t.Fatal(err)
+		}
+		if got := raters(list(t, "?sort=recent")); got != "u1,u2" {
+			t.Fatalf("raters = %s, want u1,u2", got)
+		}
+	})
+	t.Run("paged", func(t *testing.T) {
+		first := list(t, "?limit=1")
+		if first.NextCursor == nil {
+			t.Fatal("first page has no cursor")
+		}
+		second := list(t, "?limit=1&cursor="+*first.NextCursor)
+		if raters(first)+","+raters(second) != "u2,u1" || second.NextCursor != nil {
+			t.Fatalf("pages = %s then %s (next %v)", raters(first), raters(second), second.NextCursor)
+		}
+	})
+	t.Run("unknown sort", func(t *testing.T) {
+		expectStatus(t, do(engine, "GET", "/movies/Heat/reviews?sort=longest", ""), http.StatusBadRequest)
+	})
+}
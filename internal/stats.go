@@ -0,0 +1,58 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statsQueryTimeout bounds the catalogue counts run on every /metrics scrape.
+const statsQueryTimeout = 2 * time.Second
+
+var (
+	moviesDesc  = prometheus.NewDesc("movies_total", "Movies in the catalogue per tenant.", []string{"tenant"}, nil)
+	ratingsDesc = prometheus.NewDesc("ratings_total", "Ratings submitted per tenant.", []string{"tenant"}, nil)
+)
+
+// statsCollector reports business gauges by counting rows at scrape time.
+type statsCollector struct {
+	db *sql.DB
+}
+
+// NewStatsCollector returns a Prometheus collector for the movie and rating totals stored in db.
+func NewStatsCollector(db *sql.DB) prometheus.Collector {
+	return statsCollector{db: db}
+}
+
+func (s statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- moviesDesc
+	ch <- ratingsDesc
+}
+
+func (s statsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), statsQueryTimeout)
+	defer cancel()
+	s.collectCounts(ctx, ch, moviesDesc, `SELECT tenant_id, COUNT(*) FROM movies GROUP BY tenant_id`)
+	s.collectCounts(ctx, ch, ratingsDesc, `SELECT tenant_id, COUNT(*) FROM ratings GROUP BY tenant_id`)
+}
+
+func (s statsCollector) collectCounts(ctx context.Context, ch chan<- prometheus.Metric, desc *prometheus.Desc, query string) {
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		hlog.Warnf("stats collector: %v", err)
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tenant string
+		var n int64
+		if err := rows.Scan(&tenant, &n); err != nil {
+			hlog.Warnf("stats collector: %v", err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(n), tenant)
+	}
+}
@@ -0,0 +1,300 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"Robin-Camp/internal/boxoffice"
+	"Robin-Camp/internal/metrics"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	// DefaultTenant owns every row created before tenants existed and serves requests that name no tenant.
+	DefaultTenant = "default"
+
+	tenantHeader      = "X-Tenant-Id"
+	tenantKey         = "tenantId"
+	tenantExplicitKey = "tenantExplicit"
+)
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// tenantBoxClient caches a box office client built from a tenant's own credentials.
+type tenantBoxClient struct {
+	url, apiKey string
+	client      BoxOfficeClient
+	upstream    UpstreamProber
+}
+
+// WithTenantBoxOfficeOptions sets the options, such as the circuit breaker, used to build clients from tenant credentials.
+func WithTenantBoxOfficeOptions(opts ...boxoffice.Option) HandlerOption {
+	return func(h *Handler) {
+		h.tenantBoxOptions = opts
+	}
+}
+
+// currentTenant returns the tenant resolved for the request.
+func currentTenant(c *app.RequestContext) string {
+	if t := c.GetString(tenantKey); t != "" {
+		return t
+	}
+	return DefaultTenant
+}
+
+// tenantExplicit reports whether the tenant came from the X-Tenant-Id header or a host mapping rather than the default.
+func tenantExplicit(c *app.RequestContext) bool {
+	return c.GetBool(tenantExplicitKey)
+}
+
+// checkTenantExists returns ErrUnknownTenant unless tenant is empty or names an existing tenant.
+func checkTenantExists(ctx context.Context, tx *sql.Tx, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM tenants WHERE id = ?)`, tenant).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+	}
+	return nil
+}
+
+// resolveTenant selects the tenant from the X-Tenant-Id header, then the Host mapping, then the default tenant.
+// A tenant claim in a bearer token is applied later by requireScope.
+func (h *Handler) resolveTenant(next app.HandlerFunc) app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		if id := strings.TrimSpace(string(c.GetHeader(tenantHeader))); id != "" {
+			var exists bool
+			if err := h.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM tenants WHERE id = ?)`, id).Scan(&exists); err != nil {
+				internalError(ctx, c, err)
+				return
+			}
+			if !exists {
+				writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "unknown tenant " + id})
+				return
+			}
+			c.Set(tenantKey, id)
+			c.Set(tenantExplicitKey, true)
+			next(ctx, c)
+			return
+		}
+
+		host := strings.ToLower(string(c.Host()))
+		if hostname, _, err := net.SplitHostPort(host); err == nil {
+			host = hostname
+		}
+		var id string
+		err := h.db.QueryRowContext(ctx, `SELECT tenant_id FROM tenant_hosts WHERE host = ?`, host).Scan(&id)
+		switch {
+		case err == nil:
+			c.Set(tenantKey, id)
+			c.Set(tenantExplicitKey, true)
+		case errors.Is(err, sql.ErrNoRows):
+			c.Set(tenantKey, DefaultTenant)
+		default:
+			internalError(ctx, c, err)
+			return
+		}
+		next(ctx, c)
+	}
+}
+
+// boxClientFor returns the tenant's own box office client when it has credentials, otherwise the shared client.
+func (h *Handler) boxClientFor(ctx context.Context, tenantID string) (BoxOfficeClient, error) {
+	var url, apiKey sql.NullString
+	if err := h.db.QueryRowContext(ctx, `SELECT boxoffice_url, boxoffice_api_key FROM tenants WHERE id = ?`, tenantID).Scan(&url, &apiKey); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("load tenant: %w", err)
+	}
+	if url.String == "" || apiKey.String == "" {
+		return h.boxClient, nil
+	}
+
+	h.mu.RLock()
+	cached, ok := h.tenantClients[tenantID]
+	h.mu.RUnlock()
+	if ok && cached.url == url.String && cached.apiKey == apiKey.String {
+		return cached.client, nil
+	}
+
+	bc, err := boxoffice.NewClient(url.String, apiKey.String, h.tenantBoxOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("tenant box office client: %w", err)
+	}
+	client := metrics.InstrumentBoxOffice(bc)
+	h.mu.Lock()
+	if h.tenantClients == nil {
+		h.tenantClients = make(map[string]tenantBoxClient)
+	}
+	h.tenantClients[tenantID] = tenantBoxClient{url: url.String, apiKey: apiKey.String, client: client, upstream: bc}
+	h.mu.Unlock()
+	return client, nil
+}
+
+// tenantCircuits returns the circuit state of every tenant client built so far, keyed by tenant.
+func (h *Handler) tenantCircuits() map[string]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	circuits := make(map[string]string, len(h.tenantClients))
+	for id, cached := range h.tenantClients {
+		circuits[id] = cached.upstream.CircuitState()
+	}
+	return circuits
+}
+
+// listTenants godoc
+// @Summary      List tenants
+// @Description  Returns every tenant with its host mappings. Box office API keys are never returned.
+// @Tags         Admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {array}   Tenant
+// @Failure      401  {object}  Error  "Unauthorized"
+// @Failure      403  {object}  Error  "Forbidden (token lacks tenants:admin or is bound to a tenant)"
+// @Failure      500  {object}  Error  "Internal server error"
+// @Router       /admin/tenants [get]
+func (h *Handler) listTenants(ctx context.Context, c *app.RequestContext) {
+	rows, err := h.db.QueryContext(ctx, `SELECT id, name, boxoffice_url, boxoffice_api_key IS NOT NULL AND boxoffice_api_key != '', created_at FROM tenants ORDER BY id`)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	tenants := []Tenant{}
+	for rows.Next() {
+		t := Tenant{Hosts: []string{}}
+		if err := rows.Scan(&t.ID, &t.Name, &t.BoxOfficeURL, &t.HasBoxOfficeAPIKey, &t.CreatedAt); err != nil {
+			rows.Close()
+			internalError(ctx, c, err)
+			return
+		}
+		tenants = append(tenants, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+
+	hostRows, err := h.db.QueryContext(ctx, `SELECT tenant_id, host FROM tenant_hosts ORDER BY host`)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	defer hostRows.Close()
+	byID := make(map[string]*Tenant, len(tenants))
+	for i := range tenants {
+		byID[tenants[i].ID] = &tenants[i]
+	}
+	for hostRows.Next() {
+		var id, host string
+		if err := hostRows.Scan(&id, &host); err != nil {
+			internalError(ctx, c, err)
+			return
+		}
+		if t := byID[id]; t != nil {
+			t.Hosts = append(t.Hosts, host)
+		}
+	}
+	if err := hostRows.Err(); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, tenants)
+}
+
+// putTenant godoc
+// @Summary      Create or update a tenant
+// @Description  Creates the tenant or replaces its name and host mappings. Box office credentials are only changed when present in the body; send empty strings to fall back to the shared credentials.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        tenantId  path      string        true  "Tenant ID (lowercase letters, digits and dashes)"
+// @Param        tenant    body      TenantUpsert  true  "Tenant settings"
+// @Success      200       {object}  Tenant
+// @Failure      400       {object}  Error  "Bad request"
+// @Failure      401       {object}  Error  "Unauthorized"
+// @Failure      403       {object}  Error  "Forbidden (token lacks tenants:admin or is bound to a tenant)"
+// @Failure      409       {object}  Error  "Host already mapped to another tenant"
+// @Failure      422       {object}  Error  "Invalid tenant ID or settings"
+// @Failure      500       {object}  Error  "Internal server error"
+// @Router       /admin/tenants/{tenantId} [put]
+func (h *Handler) putTenant(ctx context.Context, c *app.RequestContext) {
+	id := c.Param("tenantId")
+	var payload TenantUpsert
+	if err := bindJSON(c, &payload); err != nil {
+		invalidBody(c, http.StatusBadRequest, err)
+		return
+	}
+	payload.Name = strings.TrimSpace(payload.Name)
+	if !tenantIDPattern.MatchString(id) || payload.Name == "" {
+		writeError(c, http.StatusUnprocessableEntity, Error{Code: "BAD_REQUEST", Message: "tenant id must match [a-z0-9][a-z0-9-]* and name is required"})
+		return
+	}
+	hosts := make([]string, 0, len(payload.Hosts))
+	for _, host := range payload.Hosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	var conflict string
+	err := WithTx(ctx, h.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO tenants (id, name) VALUES (?, ?) ON CONFLICT(id) DO UPDATE SET name = excluded.name`, id, payload.Name); err != nil {
+			return fmt.Errorf("upsert tenant: %w", err)
+		}
+		if payload.BoxOfficeURL != nil {
+			if _, err := tx.ExecContext(ctx, `UPDATE tenants SET boxoffice_url = ? WHERE id = ?`, nullIfEmpty(*payload.BoxOfficeURL), id); err != nil {
+				return err
+			}
+		}
+		if payload.BoxOfficeAPIKey != nil {
+			if _, err := tx.ExecContext(ctx, `UPDATE tenants SET boxoffice_api_key = ? WHERE id = ?`, nullIfEmpty(*payload.BoxOfficeAPIKey), id); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tenant_hosts WHERE tenant_id = ?`, id); err != nil {
+			return err
+		}
+		for _, host := range hosts {
+			var owner string
+			err := tx.QueryRowContext(ctx, `SELECT tenant_id FROM tenant_hosts WHERE host = ?`, host).Scan(&owner)
+			if err == nil {
+				conflict = fmt.Sprintf("host %s is mapped to tenant %s", host, owner)
+				return errors.New(conflict)
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO tenant_hosts (host, tenant_id) VALUES (?, ?)`, host, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if conflict != "" {
+			writeError(c, http.StatusConflict, Error{Code: "CONFLICT", Message: conflict})
+			return
+		}
+		internalError(ctx, c, err)
+		return
+	}
+
+	t := Tenant{ID: id, Hosts: hosts}
+	if err := h.db.QueryRowContext(ctx, `SELECT name, boxoffice_url, boxoffice_api_key IS NOT NULL AND boxoffice_api_key != '', created_at FROM tenants WHERE id = ?`, id).
+		Scan(&t.Name, &t.BoxOfficeURL, &t.HasBoxOfficeAPIKey, &t.CreatedAt); err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
@@ -0,0 +1,232 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"Robin-Camp/internal/boxoffice"
+)
+
+func TestTenantScoping(t *testing.T) {
+	h, engine := newTestServer(t, testAuthToken, WithAPIKeyAuth(true))
+	expectStatus(t, do(engine, "PUT", "/admin/tenants/acme", `{"name":"Acme"}`, bearer(testAuthToken)...), http.StatusOK)
+	createTestMovie(t, engine, "Heat", "")
+	createTestMovie(t, engine, "Alien", "acme")
+	expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":4}`, "X-Rater-Id", "u1"), http.StatusCreated)
+	expectStatus(t, do(engine, "POST", "/movies/Alien/ratings", `{"rating":3}`, "X-Rater-Id", "u1", tenantHeader, "acme"), http.StatusCreated)
+
+	unbound := createTestAPIKey(t, engine, "unbound", "", ScopeMoviesWrite, ScopeRatingsAdmin)
+	acme := createTestAPIKey(t, engine, "acme", "acme", ScopeMoviesWrite, ScopeRatingsAdmin)
+	movie := func(title string) string {
+		return `{"title":"` + title + `","genre":"Drama","releaseDate":"2020-01-01"}`
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers []string
+		want    int
+	}{
+		{"movie is visible in its tenant", "GET", "/movies/Alien", "", []string{tenantHeader, "acme"}, http.StatusOK},
+		{"movie is hidden from other tenants", "GET", "/movies/Alien", "", nil, http.StatusNotFound},
+		{"unknown tenant is rejected", "GET", "/movies/Alien", "", []string{tenantHeader, "nope"}, http.StatusBadRequest},
+		{"unbound key acts on the default tenant", "POST", "/movies", movie("Ran"), bearer(unbound), http.StatusCreated},
+		{"unbound key cannot select a tenant", "POST", "/movies", movie("Ran"), append(bearer(unbound), tenantHeader, "acme"), http.StatusForbidden},
+		{"bound key acts on its tenant", "POST", "/movies", movie("Ran"), bearer(acme), http.StatusCreated},
+		{"bound key cannot select another tenant", "POST", "/movies", movie("Up"), append(bearer(acme), tenantHeader, DefaultTenant), http.StatusForbidden},
+		{"static token selects any tenant", "GET", "/admin/rating-events?raterId=u1", "", append(bearer(testAuthToken), tenantHeader, "acme"), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectStatus(t, do(engine, tt.method, tt.path, tt.body, tt.headers...), tt.want)
+		})
+	}
+
+	t.Run("bound key cannot mint keys for another tenant", func(t *testing.T) {
+		admin := createTestAPIKey(t, engine, "acme-admin", "acme", ScopeAPIKeysAdmin, ScopeMoviesWrite)
+		w := do(engine, "POST", "/admin/api-keys", `{"name":"escape","scopes":["movies:write"],"tenant":"default"}`, bearer(admin)...)
+		expectStatus(t, w, http.StatusForbidden)
+
+		w = do(engine, "POST", "/admin/api-keys", `{"name":"inherit","scopes":["movies:write"]}`, bearer(admin)...)
+		expectStatus(t, w, http.StatusCreated)
+		var k APIKeySecret
+		decode(t, w, &k)
+		if k.Tenant == nil || *k.Tenant != "acme" {
+			t.Fatalf("tenant = %v, want acme", k.Tenant)
+		}
+	})
+
+	t.Run("bound admin only manages keys of its tenant", func(t *testing.T) {
+		admin := createTestAPIKey(t, engine, "acme-keys-admin", "acme", ScopeAPIKeysAdmin, ScopeMoviesWrite, ScopeRatingsAdmin)
+		w := do(engine, "GET", "/admin/api-keys", "", bearer(admin)...)
+		expectStatus(t, w, http.StatusOK)
+		var page APIKeyPage
+		decode(t, w, &page)
+		for _, k := range page.Items {
+			if k.Tenant == nil || *k.Tenant != "acme" {
+				t.Errorf("listed key %s of tenant %v", k.Name, k.Tenant)
+			}
+		}
+
+		expectStatus(t, do(engine, "POST", "/admin/api-keys/unbound/rotate", "", bearer(admin)...), http.StatusNotFound)
+		expectStatus(t, do(engine, "DELETE", "/admin/api-keys/unbound", "", bearer(admin)...), http.StatusNotFound)
+		expectStatus(t, do(engine, "POST", "/movies", movie("Zodiac"), bearer(unbound)...), http.StatusCreated)
+		expectStatus(t, do(engine, "POST", "/admin/api-keys/acme/rotate", "", bearer(admin)...), http.StatusOK)
+
+		w = do(engine, "GET", "/admin/api-keys", "", bearer(testAuthToken)...)
+		decode(t, w, &page)
+		if !slices.ContainsFunc(page.Items, func(k APIKey) bool { return k.Name == "unbound" }) {
+			t.Error("unbound caller does not see keys outside the tenant")
+		}
+	})
+
+	t.Run("bound admin only manages OAuth clients of its tenant", func(t *testing.T) {
+		admin := createTestAPIKey(t, engine, "acme-clients-admin", "acme", ScopeAPIKeysAdmin, ScopeMoviesRead)
+		expectStatus(t, do(engine, "POST", "/admin/oauth-clients", `{"name":"global","scopes":["movies:read"]}`, bearer(testAuthToken)...), http.StatusCreated)
+		expectStatus(t, do(engine, "POST", "/admin/oauth-clients", `{"name":"acme-app","scopes":["movies:read"]}`, bearer(admin)...), http.StatusCreated)
+
+		w := do(engine, "GET", "/admin/oauth-clients", "", bearer(admin)...)
+		expectStatus(t, w, http.StatusOK)
+		var page OAuthClientPage
+		decode(t, w, &page)
+		if len(page.Items) != 1 || page.Items[0].Name != "acme-app" {
+			t.Errorf("listed clients %+v, want only acme-app", page.Items)
+		}
+		expectStatus(t, do(engine, "DELETE", "/admin/oauth-clients/global", "", bearer(admin)...), http.StatusNotFound)
+		expectStatus(t, do(engine, "DELETE", "/admin/oauth-clients/acme-app", "", bearer(admin)...), http.StatusNoContent)
+	})
+
+	t.Run("credentials cannot exceed the caller's scopes", func(t *testing.T) {
+		admin := createTestAPIKey(t, engine, "acme-narrow-admin", "acme", ScopeAPIKeysAdmin, ScopeMoviesRead)
+		tests := []struct {
+			name, method, path, body string
+			want                     int
+		}{
+			{"scope the caller holds", "POST", "/admin/api-keys", `{"name":"reader","scopes":["movies:read"]}`, http.StatusCreated},
+			{"scope the caller lacks", "POST", "/admin/api-keys", `{"name":"writer","scopes":["ratings:admin"]}`, http.StatusForbidden},
+			{"OAuth client with a scope the caller lacks", "POST", "/admin/oauth-clients", `{"name":"writer-app","scopes":["movies:write"]}`, http.StatusForbidden},
+			{"rotating a key with broader scopes", "POST", "/admin/api-keys/acme/rotate", "", http.StatusForbidden},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				expectStatus(t, do(engine, tt.method, tt.path, tt.body, bearer(admin)...), tt.want)
+			})
+		}
+
+		for _, scope := range globalScopes {
+			payload := `{"name":"global-` + scope + `","scopes":["` + scope + `"],"tenant":"acme"}`
+			expectStatus(t, do(engine, "POST", "/admin/api-keys", payload, bearer(testAuthToken)...), http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("bound credentials cannot use global routes", func(t *testing.T) {
+		key := createTestAPIKey(t, engine, "acme-ops", "acme", ScopeMoviesRead)
+		// Even the admin role, which grants every scope, does not reach routes that act on all tenants.
+		expectStatus(t, do(engine, "PUT", "/admin/role-assignments/principal/acme-ops/admin", "", append(bearer(testAuthToken), tenantHeader, "acme")...), http.StatusCreated)
+		for _, r := range []struct{ method, path, body string }{
+			{"GET", "/admin/tenants", ""},
+			{"PUT", "/admin/tenants/default", `{"name":"Default","boxOfficeUrl":"http://attacker.example"}`},
+			{"GET", "/admin/backups", ""},
+			{"GET", "/admin/role-assignments", ""},
+		} {
+			expectStatus(t, do(engine, r.method, r.path, r.body, bearer(key)...), http.StatusForbidden)
+		}
+	})
+
+	t.Run("roles apply only in the tenant they were assigned in", func(t *testing.T) {
+		expectStatus(t, do(engine, "PUT", "/admin/role-assignments/rater/u2/viewer", "", append(bearer(testAuthToken), tenantHeader, "acme")...), http.StatusCreated)
+		expectStatus(t, do(engine, "POST", "/movies/Alien/ratings", `{"rating":2}`, "X-Rater-Id", "u2", tenantHeader, "acme"), http.StatusForbidden)
+		expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":2}`, "X-Rater-Id", "u2"), http.StatusCreated)
+
+		w := do(engine, "GET", "/admin/role-assignments?subject=u2", "", bearer(testAuthToken)...)
+		expectStatus(t, w, http.StatusOK)
+		var page RoleAssignmentPage
+		decode(t, w, &page)
+		if len(page.Items) != 0 {
+			t.Fatalf("default tenant lists %+v, want no assignments", page.Items)
+		}
+		expectStatus(t, do(engine, "DELETE", "/admin/role-assignments/rater/u2/viewer", "", bearer(testAuthToken)...), http.StatusNotFound)
+		expectStatus(t, do(engine, "DELETE", "/admin/role-assignments/rater/u2/viewer", "", append(bearer(testAuthToken), tenantHeader, "acme")...), http.StatusNoContent)
+	})
+
+	t.Run("abuse flags apply only in the tenant of the movie", func(t *testing.T) {
+		for _, rater := range []string{"burst1", "burst2"} {
+			expectStatus(t, do(engine, "POST", "/movies/Alien/ratings", `{"rating":1}`, "X-Rater-Id", rater, tenantHeader, "acme"), http.StatusCreated)
+		}
+		// Alien has three first-time raters within the window and Heat two, so only Alien's are flagged.
+		n, err := detectNewRaterBursts(context.Background(), h.db, time.Now().UTC(), time.Hour, 3)
+		if err != nil || n != 3 {
+			t.Fatalf("flagged %d, %v; want 3", n, err)
+		}
+
+		expectStatus(t, do(engine, "GET", "/movies/Alien/rating?trustedOnly=true", "", tenantHeader, "acme"), http.StatusNotFound)
+		w := do(engine, "GET", "/movies/Heat/rating?trustedOnly=true", "")
+		expectStatus(t, w, http.StatusOK)
+		var agg RatingAggregate
+		decode(t, w, &agg)
+		if agg.Count != 2 {
+			t.Fatalf("Heat trusted count = %d, want 2", agg.Count)
+		}
+
+		w = do(engine, "GET", "/admin/flagged-raters", "", bearer(testAuthToken)...)
+		expectStatus(t, w, http.StatusOK)
+		var page FlaggedRaterPage
+		decode(t, w, &page)
+		if len(page.Items) != 0 {
+			t.Fatalf("default tenant lists %+v, want no flags", page.Items)
+		}
+		expectStatus(t, do(engine, "DELETE", "/admin/flagged-raters/u1", "", bearer(testAuthToken)...), http.StatusNotFound)
+		expectStatus(t, do(engine, "DELETE", "/admin/flagged-raters/u1", "", append(bearer(testAuthToken), tenantHeader, "acme")...), http.StatusNoContent)
+	})
+
+	t.Run("rating events stay within the tenant", func(t *testing.T) {
+		for tenant, want := range map[string]string{DefaultTenant: "Heat", "acme": "Alien"} {
+			w := do(engine, "GET", "/admin/rating-events?raterId=u1", "", append(bearer(testAuthToken), tenantHeader, tenant)...)
+			expectStatus(t, w, http.StatusOK)
+			var page RatingEventPage
+			decode(t, w, &page)
+			if len(page.Items) != 1 || page.Items[0].MovieTitle == nil || *page.Items[0].MovieTitle != want {
+				t.Fatalf("tenant %s: events = %+v, want one event for %s", tenant, page.Items, want)
+			}
+		}
+	})
+}
+
+func TestTenantBoxOfficeCircuit(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(upstream.Close)
+
+	_, engine := newTestServer(t, testAuthToken,
+		WithTenantBoxOfficeOptions(boxoffice.WithCircuitBreaker(1, time.Hour)),
+		WithHealthChecks(nil, time.Nanosecond))
+	tenant := `{"name":"Acme","boxOfficeUrl":"` + upstream.URL + `","boxOfficeApiKey":"acme-key"}`
+	expectStatus(t, do(engine, "PUT", "/admin/tenants/acme", tenant, bearer(testAuthToken)...), http.StatusOK)
+
+	readyz := func(t *testing.T) HealthCheck {
+		t.Helper()
+		var report HealthReport
+		w := do(engine, "GET", "/readyz", "")
+		expectStatus(t, w, http.StatusOK)
+		decode(t, w, &report)
+		return report.Checks["boxOffice"]
+	}
+	if check := readyz(t); check.Status != HealthDisabled {
+		t.Fatalf("before any lookup: %+v, want disabled", check)
+	}
+
+	// One failed lookup opens the tenant's circuit under the configured threshold.
+	createTestMovie(t, engine, "Alien", "acme")
+	check := readyz(t)
+	circuits, _ := check.Details["tenantCircuits"].(map[string]any)
+	if check.Status != HealthDegraded || circuits["acme"] != boxoffice.CircuitOpen {
+		t.Fatalf("after failed lookup: %+v, want degraded with acme open", check)
+	}
+}
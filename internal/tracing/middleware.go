@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request, continuing the caller's trace when the request carries
+// a traceparent header. Handlers receive the span in ctx, so queries and upstream calls become its children.
+func Middleware() app.HandlerFunc {
+	return func(ctx context.Context, c *app.RequestContext) {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{&c.Request.Header})
+
+		method := string(c.Method())
+		route := c.FullPath()
+		name := method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := Tracer().Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(string(c.Path())),
+			),
+		)
+		defer span.End()
+
+		c.Next(ctx)
+
+		status := c.Response.StatusCode()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", status))
+		}
+	}
+}
+
+// headerCarrier adapts Hertz request headers to the OpenTelemetry propagator.
+type headerCarrier struct {
+	h *protocol.RequestHeader
+}
+
+func (c headerCarrier) Get(key string) string { return string(c.h.Peek(key)) }
+
+func (c headerCarrier) Set(key, value string) { c.h.Set(key, value) }
+
+func (c headerCarrier) Keys() []string {
+	var keys []string
+	c.h.VisitAll(func(k, _ []byte) {
+		keys = append(keys, string(k))
+	})
+	return keys
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// conn is the set of driver interfaces the wrapped SQLite connection forwards.
+type conn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+// WrapDriver returns a driver that starts a client span for every Exec and Query made with a context
+// that carries a trace. The span holds the statement text with placeholders, never the bound values.
+// Queries issued outside a trace, such as migrations and background jobs, are not recorded.
+func WrapDriver(d driver.Driver) driver.Driver {
+	return tracedDriver{Driver: d}
+}
+
+type tracedDriver struct {
+	driver.Driver
+}
+
+func (d tracedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	full, ok := c.(conn)
+	if !ok {
+		return c, nil
+	}
+	return tracedConn{conn: full}, nil
+}
+
+type tracedConn struct {
+	conn
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Result, err error) {
+	ctx, span := startQuery(ctx, "db.exec", query)
+	defer func() { End(span, err) }()
+	return c.conn.ExecContext(ctx, query, args)
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (_ driver.Rows, err error) {
+	ctx, span := startQuery(ctx, "db.query", query)
+	defer func() { End(span, err) }()
+	return c.conn.QueryContext(ctx, query, args)
+}
+
+func startQuery(ctx context.Context, name, query string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemNameSQLite, semconv.DBQueryText(query)),
+	)
+}
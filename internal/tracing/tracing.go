@@ -0,0 +1,80 @@
+// Package tracing configures OpenTelemetry tracing and instruments HTTP requests and database queries.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span exporters accepted by Setup.
+const (
+	// ExporterNone records no spans; trace context is still propagated.
+	ExporterNone = "none"
+	// ExporterOTLP sends spans over OTLP/HTTP, by default to a collector on localhost:4318
+	// (see OTEL_EXPORTER_OTLP_ENDPOINT).
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes each span as JSON to standard output as soon as it ends.
+	ExporterStdout = "stdout"
+)
+
+const instrumentationName = "Robin-Camp"
+
+// Tracer returns the tracer used for the service's own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Setup installs the global tracer provider for exporter and the W3C trace context propagator.
+// The returned function flushes buffered spans and must be called before the process exits.
+// Sampling follows OTEL_TRACES_SAMPLER and OTEL_TRACES_SAMPLER_ARG (parent-based always-on by default).
+func Setup(ctx context.Context, exporter, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var export sdktrace.TracerProviderOption
+	switch exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exp, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("otlp trace exporter: %w", err)
+		}
+		export = sdktrace.WithBatcher(exp)
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("stdout trace exporter: %w", err)
+		}
+		export = sdktrace.WithSyncer(exp)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want %s, %s or %s)", exporter, ExporterOTLP, ExporterStdout, ExporterNone)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("trace resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(export, sdktrace.WithResource(res))
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// End records err on span, marking it failed, and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,214 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+const (
+	maxTrendingWindow    = 90 * 24 * time.Hour
+	defaultTrendingLimit = 10
+	maxTrendingLimit     = 100
+	trendingCacheTTL     = 30 * time.Second
+	// maxTrendingCacheEntries bounds the cache, since every distinct window a client sends gets its own entry.
+	maxTrendingCacheEntries = 1024
+
+	// sqliteTimeLayout matches STRFTIME('%Y-%m-%dT%H:%M:%fZ') so string comparison orders correctly.
+	sqliteTimeLayout = "2006-01-02T15:04:05.000Z"
+)
+
+type trendingCacheEntry struct {
+	page    TrendingPage
+	expires time.Time
+}
+
+// parseWindow accepts Go durations ("36h") as well as whole days ("7d").
+func parseWindow(raw string) (time.Duration, error) {
+	var d time.Duration
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window %q", raw)
+		}
+		// Checked before multiplying, which would overflow Duration for large day counts.
+		if n <= 0 || n > int(maxTrendingWindow/(24*time.Hour)) {
+			return 0, fmt.Errorf("window must be between 1s and %s", maxTrendingWindow)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(raw); err != nil {
+			return 0, fmt.Errorf("invalid window %q", raw)
+		}
+	}
+	if d <= 0 || d > maxTrendingWindow {
+		return 0, fmt.Errorf("window must be between 1s and %s", maxTrendingWindow)
+	}
+	return d, nil
+}
+
+// getTrending godoc
+// @Summary      List trending movies
+// @Description  Ranks movies by recent rating activity and score momentum. Each rating inside the window is weighted by an exponential decay on its age (half-life of half the window); momentum is the weighted recent average minus the all-time average. Results are cached briefly.
+// @Tags         Movies
+// @Produce      json
+// @Param        window  query     string  false  "Look-back window, e.g. 24h or 7d (default 7d, max 90d)"
+// @Param        limit   query     int     false  "Maximum number of items to return (default 10, max 100)"
+// @Success      200     {object}  TrendingPage
+// @Failure      400     {object}  Error  "Invalid window or limit"
+// @Failure      500     {object}  Error  "Internal server error"
+// @Router       /movies/trending [get]
+func (h *Handler) getTrending(ctx context.Context, c *app.RequestContext) {
+	rawWindow := c.Query("window")
+	if rawWindow == "" {
+		rawWindow = "7d"
+	}
+	window, err := parseWindow(rawWindow)
+	if err != nil {
+		writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+
+	limit := defaultTrendingLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		v, err := strconv.Atoi(limitStr)
+		if err != nil || v <= 0 || v > maxTrendingLimit {
+			writeError(c, http.StatusBadRequest, Error{Code: "BAD_REQUEST", Message: "invalid limit"})
+			return
+		}
+		limit = v
+	}
+
+	tenantID := currentTenant(c)
+	// Keyed on the parsed window so that spellings of the same duration ("7d", "168h") share an entry.
+	key := tenantID + "|" + window.String() + "|" + strconv.Itoa(limit)
+	now := time.Now().UTC()
+
+	h.mu.RLock()
+	entry, ok := h.trendingCache[key]
+	h.mu.RUnlock()
+	if ok && now.Before(entry.expires) {
+		page := entry.page
+		page.Window = rawWindow
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	items, err := trendingFromDB(ctx, h.db, tenantID, now, window, limit)
+	if err != nil {
+		internalError(ctx, c, err)
+		return
+	}
+	page := TrendingPage{Window: rawWindow, GeneratedAt: now.Format(time.RFC3339Nano), Items: items}
+
+	h.mu.Lock()
+	h.storeTrending(key, trendingCacheEntry{page: page, expires: now.Add(trendingCacheTTL)}, now)
+	h.mu.Unlock()
+
+	c.JSON(http.StatusOK, page)
+}
+
+// storeTrending caches entry under key after dropping expired entries. When the cache is still full an
+// arbitrary entry is evicted, so its size never exceeds maxTrendingCacheEntries. h.mu must be held.
+func (h *Handler) storeTrending(key string, entry trendingCacheEntry, now time.Time) {
+	if h.trendingCache == nil {
+		h.trendingCache = make(map[string]trendingCacheEntry)
+	}
+	for k, e := range h.trendingCache {
+		if !now.Before(e.expires) {
+			delete(h.trendingCache, k)
+		}
+	}
+	if _, ok := h.trendingCache[key]; !ok && len(h.trendingCache) >= maxTrendingCacheEntries {
+		for k := range h.trendingCache {
+			delete(h.trendingCache, k)
+			break
+		}
+	}
+	h.trendingCache[key] = entry
+}
+
+func trendingFromDB(ctx context.Context, db *sql.DB, tenantID string, now time.Time, window time.Duration, limit int) ([]TrendingMovie, error) {
+	cutoff := now.Add(-window).Format(sqliteTimeLayout)
+	// The all-time averages are computed once per movie with recent activity rather than once per recent rating.
+	rows, err := db.QueryContext(ctx, `WITH recent AS (
+            SELECT movie_id, rating, updated_at FROM ratings WHERE tenant_id = ? AND updated_at >= ? AND `+visibleRatingClause+`
+        ), all_time AS (
+            SELECT movie_id, AVG(rating) AS average FROM ratings WHERE movie_id IN (SELECT movie_id FROM recent) AND `+visibleRatingClause+` GROUP BY movie_id
+        )
+        SELECT m.id, m.title, r.rating, r.updated_at, a.average FROM recent r JOIN movies m ON m.id = r.movie_id JOIN all_time a ON a.movie_id = r.movie_id`,
+		tenantID, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type acc struct {
+		movie    TrendingMovie
+		weight   float64
+		weighted float64
+		allTime  float64
+	}
+	byID := make(map[string]*acc)
+	// Decay constant chosen so a rating half a window old counts half as much as a fresh one.
+	lambda := math.Ln2 / (window.Seconds() / 2)
+
+	for rows.Next() {
+		var id, title, updatedAt string
+		var rating, allTime float64
+		if err := rows.Scan(&id, &title, &rating, &updatedAt, &allTime); err != nil {
+			return nil, err
+		}
+		ts, err := time.Parse(time.RFC3339Nano, updatedAt)
+		if err != nil {
+			continue
+		}
+		age := now.Sub(ts).Seconds()
+		if age < 0 {
+			age = 0
+		}
+		w := math.Exp(-lambda * age)
+
+		a, ok := byID[id]
+		if !ok {
+			a = &acc{movie: TrendingMovie{ID: id, Title: title}, allTime: allTime}
+			byID[id] = a
+		}
+		a.movie.RecentCount++
+		a.weight += w
+		a.weighted += w * rating
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	res := make([]TrendingMovie, 0, len(byID))
+	for _, a := range byID {
+		m := a.movie
+		recentAvg := a.weighted / a.weight
+		m.RecentAverage = math.Round(recentAvg*10) / 10
+		m.Momentum = math.Round((recentAvg-a.allTime)*100) / 100
+		// Momentum lies within [-4.5, 4.5]; scale activity by at most +/-90%.
+		m.Score = math.Round(a.weight*(1+(recentAvg-a.allTime)/5)*1000) / 1000
+		res = append(res, m)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].ID < res[j].ID
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res, nil
+}
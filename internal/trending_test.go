@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{raw: "7d", want: 7 * 24 * time.Hour},
+		{raw: "36h", want: 36 * time.Hour},
+		{raw: "90d", want: maxTrendingWindow},
+		{raw: "91d", wantErr: true},
+		{raw: "0d", wantErr: true},
+		{raw: "-1d", wantErr: true},
+		// Wraps around to about 25 minutes if multiplied unchecked.
+		{raw: "213504d", wantErr: true},
+		{raw: "106752d", wantErr: true},
+		{raw: "2161h", wantErr: true},
+		{raw: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := parseWindow(tt.raw)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Fatalf("parseWindow(%q) = %s, %v; want %s, error %v", tt.raw, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTrending(t *testing.T) {
+	_, engine := newTestServer(t, testAuthToken)
+	createTestMovie(t, engine, "Heat", "")
+	createTestMovie(t, engine, "Alien", "")
+	createTestMovie(t, engine, "Ran", "")
+	for i, r := range []string{"2", "3", "5"} {
+		expectStatus(t, do(engine, "POST", "/movies/Heat/ratings", `{"rating":`+r+`}`, "X-Rater-Id", "h"+strconv.Itoa(i)), http.StatusCreated)
+	}
+	expectStatus(t, do(engine, "POST", "/movies/Alien/ratings", `{"rating":4}`, "X-Rater-Id", "a1"), http.StatusCreated)
+
+	w := do(engine, "GET", "/movies/trending?window=1d", "")
+	expectStatus(t, w, http.StatusOK)
+	var page TrendingPage
+	decode(t, w, &page)
+	if len(page.Items) != 2 || page.Items[0].Title != "Heat" || page.Items[1].Title != "Alien" {
+		t.Fatalf("items = %+v, want Heat then Alien", page.Items)
+	}
+	heat := page.Items[0]
+	if heat.RecentCount != 3 || heat.RecentAverage != 3.3 || heat.Momentum != 0 {
+		t.Fatalf("Heat = %+v, want 3 recent ratings averaging 3.3 with no momentum", heat)
+	}
+}
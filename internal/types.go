@@ -30,16 +30,48 @@ type Movie struct {
 	Budget      *int64     `json:"budget,omitempty"`
 	MpaRating   *string    `json:"mpaRating,omitempty"`
 	BoxOffice   *BoxOffice `json:"boxOffice"`
+	CreatedBy   *string    `json:"createdBy,omitempty"`
+	UpdatedAt   string     `json:"-"`
 }
 
 type RatingSubmit struct {
-	Rating float64 `json:"rating"`
+	Rating float64       `json:"rating"`
+	Review *ReviewSubmit `json:"review,omitempty"`
+}
+
+type ReviewSubmit struct {
+	Title    string `json:"title,omitempty"`
+	Body     string `json:"body"`
+	Spoiler  bool   `json:"spoiler,omitempty"`
+	Language string `json:"language,omitempty"`
 }
 
 type RatingResult struct {
-	MovieTitle string  `json:"movieTitle"`
-	RaterID    string  `json:"raterId"`
-	Rating     float64 `json:"rating"`
+	MovieTitle string        `json:"movieTitle"`
+	RaterID    string        `json:"raterId"`
+	Rating     float64       `json:"rating"`
+	Review     *ReviewSubmit `json:"review,omitempty"`
+}
+
+type Review struct {
+	RaterID      string  `json:"raterId"`
+	Rating       float64 `json:"rating"`
+	Title        *string `json:"title,omitempty"`
+	Body         string  `json:"body"`
+	Spoiler      bool    `json:"spoiler"`
+	Language     *string `json:"language,omitempty"`
+	HelpfulCount int64   `json:"helpfulCount"`
+	UpdatedAt    string  `json:"updatedAt"`
+}
+
+type ReviewPage struct {
+	Items      []Review `json:"items"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+type HelpfulVoteResult struct {
+	RaterID      string `json:"raterId"`
+	HelpfulCount int64  `json:"helpfulCount"`
 }
 
 type RatingAggregate struct {
@@ -47,13 +79,277 @@ type RatingAggregate struct {
 	Count   int64   `json:"count"`
 }
 
+type RaterToken struct {
+	RaterID string `json:"raterId"`
+	Token   string `json:"token"`
+}
+
+type APIKeyCreate struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is an optional RFC 3339 timestamp after which the key stops working.
+	ExpiresAt *string `json:"expiresAt,omitempty"`
+	// Tenant binds the key to one tenant; unbound keys only act on the default tenant.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type APIKey struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Scopes     []string `json:"scopes"`
+	Tenant     *string  `json:"tenant,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+	LastUsedAt *string  `json:"lastUsedAt,omitempty"`
+	ExpiresAt  *string  `json:"expiresAt,omitempty"`
+	RevokedAt  *string  `json:"revokedAt,omitempty"`
+}
+
+// APIKeySecret is returned once when a key is created or rotated; only its hash is stored.
+type APIKeySecret struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+type APIKeyPage struct {
+	Items      []APIKey `json:"items"`
+	NextCursor *string  `json:"nextCursor,omitempty"`
+}
+
+type OAuthClientCreate struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// Tenant binds the client and the tokens it obtains to one tenant; unbound clients only act on the default tenant.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type OAuthClient struct {
+	ClientID  string   `json:"clientId"`
+	Name      string   `json:"name"`
+	Scopes    []string `json:"scopes"`
+	Tenant    *string  `json:"tenant,omitempty"`
+	CreatedAt string   `json:"createdAt"`
+	RevokedAt *string  `json:"revokedAt,omitempty"`
+}
+
+// OAuthClientSecret is returned once when a client is registered; only the secret's hash is stored.
+type OAuthClientSecret struct {
+	OAuthClient
+	ClientSecret string `json:"clientSecret"`
+}
+
+type OAuthClientPage struct {
+	Items      []OAuthClient `json:"items"`
+	NextCursor *string       `json:"nextCursor,omitempty"`
+}
+
+// OAuthToken is the RFC 6749 access token response.
+type OAuthToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// OAuthError is the RFC 6749 error response used by the /oauth endpoints.
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthIntrospection is the RFC 7662 introspection response.
+type OAuthIntrospection struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Iss       string `json:"iss,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+	Jti       string `json:"jti,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+}
+
+type Role struct {
+	Name        string   `json:"name"`
+	Permissions []string `json:"permissions"`
+}
+
+type RoleAssignment struct {
+	SubjectKind string `json:"subjectKind"`
+	Subject     string `json:"subject"`
+	Role        string `json:"role"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type RoleAssignmentPage struct {
+	Items      []RoleAssignment `json:"items"`
+	NextCursor *string          `json:"nextCursor,omitempty"`
+}
+
+// RoutePermission is one row of the route-to-permission matrix.
+type RoutePermission struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Auth       string `json:"auth"`
+	Permission string `json:"permission,omitempty"`
+}
+
+type TenantUpsert struct {
+	Name  string   `json:"name"`
+	Hosts []string `json:"hosts,omitempty"`
+	// BoxOfficeURL and BoxOfficeAPIKey override the deployment-wide box office credentials for this tenant.
+	BoxOfficeURL    *string `json:"boxOfficeUrl,omitempty"`
+	BoxOfficeAPIKey *string `json:"boxOfficeApiKey,omitempty"`
+}
+
+type Tenant struct {
+	ID                 string   `json:"id"`
+	Name               string   `json:"name"`
+	Hosts              []string `json:"hosts"`
+	BoxOfficeURL       *string  `json:"boxOfficeUrl,omitempty"`
+	HasBoxOfficeAPIKey bool     `json:"hasBoxOfficeApiKey"`
+	CreatedAt          string   `json:"createdAt"`
+}
+
+type ReportSubmit struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type ReportResult struct {
+	RaterID     string `json:"raterId"`
+	Status      string `json:"status"`
+	ReportCount int64  `json:"reportCount"`
+}
+
+type ModerationItem struct {
+	MovieID     string  `json:"movieId"`
+	MovieTitle  string  `json:"movieTitle"`
+	RaterID     string  `json:"raterId"`
+	Rating      float64 `json:"rating"`
+	ReviewTitle *string `json:"reviewTitle,omitempty"`
+	ReviewBody  *string `json:"reviewBody,omitempty"`
+	Status      string  `json:"status"`
+	Reason      *string `json:"reason,omitempty"`
+	ReportCount int64   `json:"reportCount"`
+	UpdatedAt   string  `json:"updatedAt"`
+}
+
+type ModerationPage struct {
+	Items      []ModerationItem `json:"items"`
+	NextCursor *string          `json:"nextCursor,omitempty"`
+}
+
+type ModerationDecision struct {
+	Note string `json:"note,omitempty"`
+}
+
+type FlaggedRater struct {
+	RaterID   string `json:"raterId"`
+	MovieID   string `json:"movieId"`
+	Reason    string `json:"reason"`
+	FlaggedAt string `json:"flaggedAt"`
+}
+
+type FlaggedRaterPage struct {
+	Items      []FlaggedRater `json:"items"`
+	NextCursor *string        `json:"nextCursor,omitempty"`
+}
+
+type RatingEvent struct {
+	ID         int64    `json:"id"`
+	MovieID    string   `json:"movieId"`
+	MovieTitle *string  `json:"movieTitle,omitempty"`
+	RaterID    string   `json:"raterId"`
+	Action     string   `json:"action"`
+	OldRating  *float64 `json:"oldRating,omitempty"`
+	NewRating  *float64 `json:"newRating,omitempty"`
+	ClientIP   *string  `json:"clientIp,omitempty"`
+	CreatedAt  string   `json:"createdAt"`
+}
+
+type RatingEventPage struct {
+	Items      []RatingEvent `json:"items"`
+	NextCursor *string       `json:"nextCursor,omitempty"`
+}
+
+type TrendingMovie struct {
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	Score         float64 `json:"score"`
+	RecentCount   int64   `json:"recentCount"`
+	RecentAverage float64 `json:"recentAverage"`
+	Momentum      float64 `json:"momentum"`
+}
+
+type TrendingPage struct {
+	Window      string          `json:"window"`
+	GeneratedAt string          `json:"generatedAt"`
+	Items       []TrendingMovie `json:"items"`
+}
+
 type MoviePage struct {
 	Items      []Movie `json:"items"`
 	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
+// ImportRow is the outcome of one imported record; Reason explains skipped and failed rows.
+type ImportRow struct {
+	Line   int     `json:"line"`
+	Title  string  `json:"title,omitempty"`
+	Status string  `json:"status" enums:"created,updated,skipped,failed"`
+	ID     *string `json:"id,omitempty"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+type ImportReport struct {
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
+
+// RatingExport is one line of GET /export/ratings.
+type RatingExport struct {
+	MovieID          string  `json:"movieId"`
+	MovieTitle       string  `json:"movieTitle"`
+	RaterID          string  `json:"raterId"`
+	Rating           float64 `json:"rating"`
+	ReviewTitle      *string `json:"reviewTitle,omitempty"`
+	ReviewBody       *string `json:"reviewBody,omitempty"`
+	ReviewSpoiler    bool    `json:"reviewSpoiler"`
+	ReviewLanguage   *string `json:"reviewLanguage,omitempty"`
+	ModerationStatus string  `json:"moderationStatus"`
+	UpdatedAt        string  `json:"updatedAt"`
+}
+
+type BackupInfo struct {
+	Name      string `json:"name"`
+	SizeBytes int64  `json:"sizeBytes"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	// SchemaVersion is only reported for the backup just taken.
+	SchemaVersion *int `json:"schemaVersion,omitempty"`
+}
+
 type Error struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 	Details any    `json:"details,omitempty"`
+	// RequestID matches the X-Request-Id response header and the request_id of the server's log records.
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// HealthCheck is the result of probing one dependency.
+type HealthCheck struct {
+	Status    string         `json:"status" example:"ok"`
+	LatencyMs float64        `json:"latencyMs,omitempty" example:"0.4"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// HealthReport is the overall status (ok, degraded or down) with the checks it was derived from.
+type HealthReport struct {
+	Status    string                 `json:"status" example:"ok"`
+	CheckedAt string                 `json:"checkedAt" example:"2024-01-01T12:00:00Z"`
+	Checks    map[string]HealthCheck `json:"checks,omitempty"`
 }
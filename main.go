@@ -3,64 +3,186 @@ package main
 import (
 	"Robin-Camp/api"
 	"Robin-Camp/internal"
+	"Robin-Camp/internal/compress"
+	"Robin-Camp/internal/config"
+	"Robin-Camp/internal/https"
+	"Robin-Camp/internal/lifecycle"
+	"Robin-Camp/internal/logging"
+	"Robin-Camp/internal/metrics"
+	"Robin-Camp/internal/tracing"
 	"context"
 	"flag"
 	"log"
+	"log/slog"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 
 	_ "Robin-Camp/docs"
 
 	"github.com/cloudwego/hertz/pkg/app"
 	"github.com/cloudwego/hertz/pkg/app/server"
+	hertzconfig "github.com/cloudwego/hertz/pkg/common/config"
 	"github.com/cloudwego/hertz/pkg/common/hlog"
+	"github.com/cloudwego/hertz/pkg/protocol/suite"
 	"github.com/hertz-contrib/swagger"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 )
 
 func main() {
-	// 从 .env 文件加载环境变量
+	// 从 .env 文件加载环境变量 (不覆盖已设置的环境变量)
 	err := godotenv.Load()
 	if err != nil {
 		log.Println("加载 .env 文件失败, 将使用系统环境变量")
 	}
-	internal.InitDB()
+	// config print 自行加载配置, 配置无效时也能给出错误列表
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
 
-	port := flag.String("p", "8080", "监听端口")
-	address := flag.String("a", "0.0.0.0", "监听地址")
+	// 配置优先级: 默认值 < 配置文件 (-config 或 CONFIG_FILE) < 环境变量 (含 .env) < 命令行参数
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML 配置文件")
+	port := flag.Int("p", 0, "监听端口, 覆盖 server.port (默认 8080)")
+	address := flag.String("a", "", "监听地址, 覆盖 server.address (默认 0.0.0.0)")
 	help := flag.Bool("h", false, "显示帮助")
 	swaggerFlag := flag.Bool("swagger", false, "启用Swagger文档")
-
-	// 从.env加载配置
-	envPort := os.Getenv("PORT")
-	if envPort != "" {
-		*port = envPort
-	}
-	envAddress := os.Getenv("ADDRESS")
-	if envAddress != "" {
-		*address = envAddress
+	// 子命令的参数由各子命令自行解析, 其配置只来自配置文件与环境变量
+	command := ""
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		command = os.Args[1]
+	} else {
+		flag.Parse()
 	}
-
-	flag.Parse()
 	if *help {
 		flag.Usage()
 		return
 	}
-	h := server.Default(server.WithHostPorts(*address + ":" + *port))
+	cfg, err := config.Load(*configFile, func(c *config.Config) {
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "p":
+				c.Server.Port = *port
+			case "a":
+				c.Server.Address = *address
+			case "swagger":
+				c.Server.Swagger = *swaggerFlag
+			}
+		})
+	})
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 日志: hlog 与标准库 log 均以 JSON 格式经 slog 输出到标准错误
+	logLevel, _ := logging.ParseLevel(cfg.Logging.Level)
+	logger := logging.New(os.Stderr, logLevel)
+	hlog.SetLogger(logger)
+	slog.SetDefault(logger.Slog())
+	// 恢复需在打开数据库之前执行
+	if command == "restore" {
+		os.Exit(runRestoreCommand(cfg, os.Args[2:]))
+	}
+	db, err := internal.OpenDB(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("初始化数据库失败: %v", err)
+	}
+
+	// 管理子命令: 执行完直接退出，不启动服务
+	switch command {
+	case "":
+	case "apikey":
+		os.Exit(runAPIKeyCommand(db, os.Args[2:]))
+	case "oauth-client":
+		os.Exit(runOAuthClientCommand(db, os.Args[2:]))
+	case "import":
+		os.Exit(runImportCommand(db, os.Args[2:]))
+	case "export":
+		os.Exit(runExportCommand(db, os.Args[2:]))
+	case "backup":
+		os.Exit(runBackupCommand(cfg, db, os.Args[2:]))
+	default:
+		log.Fatalf("未知子命令: %s", command)
+	}
+
+	// 链路追踪: 导出到 otlp (本地 collector) 或 stdout, 默认不导出
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.ServiceName)
+	if err != nil {
+		log.Fatalf("初始化链路追踪失败: %v", err)
+	}
+
+	hostPort := net.JoinHostPort(cfg.Server.Address, strconv.Itoa(cfg.Server.Port))
+	// 优雅退出: 收到 SIGTERM 后先让 /readyz 失败并等待 drainDelay, 再停止接收连接、等待进行中的请求,
+	// 停止后台任务, 最后合并 WAL 并关闭数据库; 整个过程不超过 shutdownTimeout
+	lc := lifecycle.New(cfg.Server.ShutdownTimeout, cfg.Server.DrainDelay)
+	serverOpts := []hertzconfig.Option{
+		server.WithHostPorts(hostPort),
+		server.WithReadTimeout(cfg.Server.ReadTimeout),
+		server.WithWriteTimeout(cfg.Server.WriteTimeout),
+		server.WithIdleTimeout(cfg.Server.IdleTimeout),
+		server.WithMaxRequestBodySize(cfg.Server.MaxBodySize),
+		server.WithExitWaitTime(cfg.Server.ShutdownTimeout),
+	}
+	// HTTPS: 证书与客户端 CA 文件变更后自动重新加载; 启用 HTTP/2 时通过 ALPN 协商
+	scheme := "http"
+	var certs *https.Certificates
+	if cfg.TLS.Enabled() {
+		certs, err = https.LoadCertificates(cfg.TLS.CertFile, cfg.TLS.KeyFile, cfg.TLS.ClientCAFile)
+		if err != nil {
+			log.Fatalf("加载 TLS 证书失败: %v", err)
+		}
+		serverOpts = append(serverOpts, server.WithTLS(certs.TLSConfig(cfg.TLS.HTTP2)), server.WithALPN(cfg.TLS.HTTP2))
+		scheme = "https"
+	}
+	h := server.Default(serverOpts...)
+	// 客户端 IP: 只有来自受信任代理的连接才采用 X-Forwarded-For / X-Real-IP, 否则使用连接的对端地址,
+	// 防止客户端伪造 IP 绕过按 IP 限流或污染评分事件中的 client_ip
+	trustedProxies, _ := cfg.Server.TrustedProxyCIDRs()
+	h.SetClientIPFunc(app.ClientIPWithOption(app.ClientIPOptions{
+		RemoteIPHeaders: []string{"X-Forwarded-For", "X-Real-IP"},
+		TrustedCIDRs:    trustedProxies,
+	}))
+	if certs != nil {
+		lc.Go("certificate reloader", func(ctx context.Context) { certs.Watch(ctx, cfg.TLS.ReloadInterval) })
+		if cfg.TLS.HTTP2 {
+			h2 := https.NewHTTP2(cfg.Server.MaxBodySize, cfg.Server.ReadTimeout, cfg.Server.WriteTimeout, cfg.Server.IdleTimeout)
+			h.AddProtocol(suite.HTTP2, h2)
+			h.OnShutdown = append(h.OnShutdown, func(ctx context.Context) { h2.Shutdown(ctx) })
+		}
+		// 可选的 HTTP 监听, 将请求重定向到 HTTPS
+		if cfg.TLS.RedirectAddress != "" {
+			lc.Serve("https redirect", https.NewRedirectServer(cfg.TLS.RedirectAddress, cfg.Server.Port))
+		}
+	}
+	// 为每个请求创建 span、分配 X-Request-Id 并写访问日志，记录所有请求（包括 404）的次数与耗时供 /metrics 使用。
+	// 状态码低于 500 的访问日志按 logging.sampleRate 采样, 5xx 总是记录
+	h.Use(tracing.Middleware(), logger.Middleware(cfg.Logging.SampleRate), metrics.Middleware())
+	// 按 Accept-Encoding 协商 zstd 或 gzip, 只压缩不小于 compression.minSize 的文本响应
+	if cfg.Compression.Enabled {
+		h.Use(compress.Middleware(cfg.Compression.MinSize))
+	}
+	// 退出时依次关闭数据库并导出缓冲中的 span
+	lc.OnStop("database", func(ctx context.Context) error { return internal.CloseDB(ctx, db) })
+	lc.OnStop("tracing", shutdownTracing)
 
 	apiRoute := h.Group("/")
 	// 注册认证路由 (公开)
-	api.RegisterRoutes(apiRoute)
+	if err := api.RegisterRoutes(apiRoute, cfg, lc, db); err != nil {
+		log.Fatalf("初始化服务失败: %v", err)
+	}
 
 	// 404 handler
 	h.NoRoute(func(ctx context.Context, c *app.RequestContext) {
 		c.JSON(404, map[string]interface{}{"message": "404 Not Found"})
 	})
 
-	if *swaggerFlag {
-		hlog.Info("Swagger文档已启用，访问 http://" + *address + ":" + *port + "/swagger/index.html 查看")
-		url := swagger.URL("http://" + *address + ":" + *port + "/swagger/doc.json")
+	if cfg.Server.Swagger {
+		hlog.Info("Swagger文档已启用，访问 " + scheme + "://" + hostPort + "/swagger/index.html 查看")
+		url := swagger.URL(scheme + "://" + hostPort + "/swagger/doc.json")
 		h.GET("/swagger/*any", swagger.WrapHandler(swaggerFiles.Handler, url))
 	}
-	h.Spin()
+	if err := lc.Run(h); err != nil {
+		os.Exit(1)
+	}
 }